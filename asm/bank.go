@@ -0,0 +1,75 @@
+package asm
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Banking bank-switched code memory, ROM holds common area followed by banks,
+// the selected bank is mapped at Window~0xFFFF
+type Banking struct {
+	Window uint                  // start of bank window, 0x8000 for 32KB banks
+	Bank   func(m *Machine) uint // selected bank by switch trigger
+}
+
+// BankSFR bank selected by SFR bits, port latch P0~P3 or custom SFR
+func BankSFR(addr uint8, mask uint8) func(m *Machine) uint {
+	shift := uint(bits.TrailingZeros8(mask))
+	return func(m *Machine) uint {
+		return uint(m.SFR[addr]&mask) >> shift
+	}
+}
+
+// BankXDATA bank selected by bits of latch register in XDATA RAM
+func BankXDATA(addr uint16, mask uint8) func(m *Machine) uint {
+	shift := uint(bits.TrailingZeros8(mask))
+	return func(m *Machine) uint {
+		return uint(m.XDATA[addr]&mask) >> shift
+	}
+}
+
+// SetBanking enable code banking, nil for flat ROM
+func (m *Machine) SetBanking(b *Banking) {
+	m.banking = b
+}
+
+// Bank selected code bank, 0 without banking
+func (m *Machine) Bank() uint {
+	if m.banking == nil {
+		return 0
+	}
+	return m.banking.Bank(m)
+}
+
+// CodeAddr ROM offset of bank:address, common area below window ignore bank
+func (m *Machine) CodeAddr(bank uint, addr uint) uint {
+	b := m.banking
+	if b == nil || addr < b.Window {
+		return addr
+	}
+	return b.Window + bank*(0x10000-b.Window) + (addr - b.Window)
+}
+
+// BankAddr bank:address of ROM offset
+func (m *Machine) BankAddr(code uint) (bank uint, addr uint) {
+	b := m.banking
+	if b == nil || code < b.Window {
+		return 0, code
+	}
+	size := 0x10000 - b.Window
+	return (code - b.Window) / size, b.Window + (code-b.Window)%size
+}
+
+// CodeString ROM offset as "bank:address" in bank window, "address" in common area
+func (m *Machine) CodeString(code uint) string {
+	if m.banking == nil || code < m.banking.Window {
+		return fmt.Sprintf("%04X", code)
+	}
+	bank, addr := m.BankAddr(code)
+	return fmt.Sprintf("%02X:%04X", bank, addr)
+}
+
+// TraceBank tracepoint at bank:address, return breakpoint ID
+func (m *Machine) TraceBank(bank uint, addr uint, fn func(m *Machine)) int {
+	return m.Trace(m.CodeAddr(bank, addr), fn)
+}
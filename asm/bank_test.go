@@ -0,0 +1,89 @@
+package asm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+func Test_Banking(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	// common area and 2 banks of 32KB
+	m.ROM = make([]byte, 0x18000)
+	copy(m.ROM[0x0000:], []byte{
+		0x75, 0x90, 0xFC, // 0000: MOV P1, #0xFC, bank 0
+		0x12, 0x80, 0x00, // 0003: LCALL 8000
+		0x75, 0x90, 0xFD, // 0006: MOV P1, #0xFD, bank 1
+		0x12, 0x80, 0x00, // 0009: LCALL 8000
+		0x80, 0xFE, // 000C: SJMP 000C
+	})
+	copy(m.ROM[0x08000:], []byte{
+		0x75, 0x40, 0x11, // 00:8000: MOV 0x40, #0x11
+		0x22, // 00:8003: RET
+	})
+	copy(m.ROM[0x10000:], []byte{
+		0x75, 0x41, 0x22, // 01:8000: MOV 0x41, #0x22
+		0x22, // 01:8003: RET
+	})
+	m.SetBanking(&asm.Banking{Window: 0x8000, Bank: asm.BankSFR(asm.P1, 0x03)})
+
+	hits := 0
+	m.TraceBank(1, 0x8000, func(m *asm.Machine) {
+		hits++
+		if m.Bank() != 1 || m.PC != 0x8000 {
+			t.Errorf("trace at %s", m.CodeString(m.CodeAddr(m.Bank(), m.PC)))
+		}
+	})
+	for i := 0; i < 8; i++ {
+		m.Single()
+	}
+	if m.DATA[0x40] != 0x11 || m.DATA[0x41] != 0x22 {
+		t.Errorf("banked call DATA[40] %02X DATA[41] %02X", m.DATA[0x40], m.DATA[0x41])
+	}
+	if hits != 1 || m.PC != 0x0C {
+		t.Errorf("bank 1 trace hits %d, PC %04X", hits, m.PC)
+	}
+
+	s, err := m.DumpFakeCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"0003\t128000\tLCALL", "00:8000\t754011\tMOV", "01:8000\t754122\tMOV", "01:8003\t22\tRET"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("DumpFakeCode missing %q", want)
+		}
+	}
+}
+
+func Test_Banking_RunTo(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = make([]byte, 0x18000)
+	copy(m.ROM[0x0000:], []byte{
+		0x75, 0x90, 0xFC, // 0000: MOV P1, #0xFC, bank 0
+		0x12, 0x80, 0x00, // 0003: LCALL 8000
+		0x75, 0x90, 0xFD, // 0006: MOV P1, #0xFD, bank 1
+		0x12, 0x80, 0x00, // 0009: LCALL 8000
+		0x80, 0xFE, // 000C: SJMP 000C
+	})
+	m.ROM[0x08000] = 0x22 // 00:8000: RET
+	m.ROM[0x10000] = 0x22 // 01:8000: RET
+	m.SetBanking(&asm.Banking{Window: 0x8000, Bank: asm.BankSFR(asm.P1, 0x03)})
+
+	h := m.RunToBank(1, 0x8000)
+	if h.Reason != asm.HaltCondition || h.Bank != 1 || h.PC != 0x8000 || h.Code != 0x10000 {
+		t.Errorf("RunToBank %s bank %d code %X", h, h.Bank, h.Code)
+	}
+	if !strings.Contains(h.String(), "01:8000") {
+		t.Errorf("halt location %s", h)
+	}
+
+	m.Reset()
+	if h := m.RunTo(0x8000); h.Bank != 0 || h.Code != 0x8000 {
+		t.Errorf("RunTo %s", h)
+	}
+	m.Reset()
+	if h := m.RunUntilBankPC(0, 0x000C); h.Reason != asm.HaltCondition || h.String() != "Condition at 000C" {
+		t.Errorf("common area %s", h)
+	}
+}
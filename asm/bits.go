@@ -0,0 +1,50 @@
+package asm
+
+// BitAddr split bit address into byte address and bit mask
+//
+//	0x00~0x7F: DATA 0x20~0x2F
+//	0x80~0xFF: SFR which address ending in 0 or 8
+func BitAddr(bit uint8) (addr uint8, mask uint8) {
+	mask = 1 << (bit & 0x07)
+	if bit < 0x80 {
+		return 0x20 + bit>>3, mask
+	}
+	return bit & 0xF8, mask
+}
+
+// ReadBit read mechine bit-addressable range
+func (m *Machine) ReadBit(bit uint8) bool {
+	addr, mask := BitAddr(bit)
+	return m.ReadDATA(addr)&mask != 0
+}
+
+// WriteBit write mechine bit-addressable range,
+// it is a read-modify-write of the byte, so DATA hooks are triggered too,
+// and port latch is read instead of pins
+func (m *Machine) WriteBit(bit uint8, val bool) {
+	addr, mask := BitAddr(bit)
+	m.rmw = true
+	old := m.ReadDATA(addr)
+	m.rmw = false
+	if val {
+		m.WriteDATA(addr, old|mask)
+	} else {
+		m.WriteDATA(addr, old&^mask)
+	}
+}
+
+// bit read bit by hardware, without hooks
+func (m *Machine) bit(bit uint8) bool {
+	addr, mask := BitAddr(bit)
+	return *m.direct(addr)&mask != 0
+}
+
+// setBit write bit by hardware, without hooks
+func (m *Machine) setBit(bit uint8, val bool) {
+	addr, mask := BitAddr(bit)
+	if val {
+		*m.direct(addr) |= mask
+	} else {
+		*m.direct(addr) &^= mask
+	}
+}
@@ -0,0 +1,62 @@
+package asm_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+func Test_Bit_Address(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.WriteDATA(asm.P1, 0x00)
+	m.WriteBit(0x00, true)
+	m.WriteBit(0x7F, true)
+	m.WriteBit(0x93, true)
+	if m.DATA[0x20] != 0x01 || m.DATA[0x2F] != 0x80 {
+		t.Errorf("DATA bit: 20:%02X 2F:%02X", m.DATA[0x20], m.DATA[0x2F])
+	}
+	if m.SFR[asm.P1] != 0x08 {
+		t.Errorf("P1.3: P1 %02X", m.SFR[asm.P1])
+	}
+	if !m.ReadBit(0x93) || m.ReadBit(0x92) {
+		t.Errorf("ReadBit P1")
+	}
+}
+
+func Test_Bit_Instructions(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.WriteDATA(asm.P1, 0x00)
+	m.ROM = []byte{
+		0xD2, 0x93, // 0000: SETB P1.3
+		0xA2, 0x93, // 0002: MOV C, P1.3
+		0x92, 0x08, // 0004: MOV 0x21.0, C
+		0xB0, 0x08, // 0006: ANL C, /0x21.0
+		0xB2, 0x0F, // 0008: CPL 0x21.7
+		0x10, 0x0F, 0x01, // 000A: JBC 0x21.7, 000E
+		0x00,       // 000D: NOP
+		0xC2, 0x93, // 000E: CLR P1.3
+	}
+	for m.PC < uint(len(m.ROM)) {
+		m.Single()
+	}
+	if m.SFR[asm.P1] != 0x00 {
+		t.Errorf("P1 %02X", m.SFR[asm.P1])
+	}
+	if m.DATA[0x21] != 0x01 {
+		t.Errorf("DATA[21] %02X", m.DATA[0x21])
+	}
+	if m.Flag(asm.FlagCY) {
+		t.Errorf("CY should be clear")
+	}
+
+	s, err := m.DumpFakeCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"P1.3(0x93)", "C /0x21.0(0x08)", "0x21.7(0x0F) C:1(000E)"} {
+		if !strings.Contains(s, want) {
+			t.Errorf("DumpFakeCode missing %q:\n%s", want, s)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package asm
+
+import "sort"
+
+// BreakpointKind action of breakpoint
+type BreakpointKind int
+
+const (
+	// BreakStop stop execution before the instruction
+	BreakStop BreakpointKind = iota
+	// BreakTrace tracepoint, call Action and continue
+	BreakTrace
+)
+
+// Breakpoint code breakpoint,
+// breakpoints can be managed by other goroutines while the run loop is running
+type Breakpoint struct {
+	ID       int  // assigned by AddBreakpoint
+	Addr     uint // ROM offset, see CodeAddr for banked code
+	Kind     BreakpointKind
+	Disabled bool
+	// Cond condition, nil always true
+	Cond func(m *Machine) bool
+	// Ignore hits ignored before trigger
+	Ignore int
+	// OneShot removed after trigger
+	OneShot bool
+	// Action called on trigger, before stop
+	Action func(m *Machine)
+	// Hits times reached with condition true
+	Hits int
+}
+
+// AddBreakpoint add breakpoint, return its ID
+func (m *Machine) AddBreakpoint(bp Breakpoint) int {
+	c := m.ctl
+	c.debug.Lock()
+	defer c.debug.Unlock()
+	c.nextBreakID++
+	bp.ID = c.nextBreakID
+	bps := c.breakpoints[bp.Addr]
+	c.breakpoints[bp.Addr] = append(bps[:len(bps):len(bps)], &bp)
+	return bp.ID
+}
+
+// findBreakpoint breakpoint by ID, nil if not found, c.debug held
+func (c *control) findBreakpoint(id int) *Breakpoint {
+	for _, bps := range c.breakpoints {
+		for _, bp := range bps {
+			if bp.ID == id {
+				return bp
+			}
+		}
+	}
+	return nil
+}
+
+// removeBreakpoint remove breakpoint from its address, c.debug held
+func (c *control) removeBreakpoint(bp *Breakpoint) {
+	bps := c.breakpoints[bp.Addr]
+	for k := range bps {
+		if bps[k] == bp {
+			bps = append(bps[:k:k], bps[k+1:]...)
+			break
+		}
+	}
+	if len(bps) == 0 {
+		delete(c.breakpoints, bp.Addr)
+	} else {
+		c.breakpoints[bp.Addr] = bps
+	}
+}
+
+// RemoveBreakpoint remove breakpoint by ID, false if not found
+func (m *Machine) RemoveBreakpoint(id int) bool {
+	c := m.ctl
+	c.debug.Lock()
+	defer c.debug.Unlock()
+	bp := c.findBreakpoint(id)
+	if bp == nil {
+		return false
+	}
+	c.removeBreakpoint(bp)
+	return true
+}
+
+// EnableBreakpoint enable or disable breakpoint by ID, false if not found
+func (m *Machine) EnableBreakpoint(id int, enabled bool) bool {
+	c := m.ctl
+	c.debug.Lock()
+	defer c.debug.Unlock()
+	bp := c.findBreakpoint(id)
+	if bp == nil {
+		return false
+	}
+	bp.Disabled = !enabled
+	return true
+}
+
+// GetBreakpoint copy of breakpoint by ID
+func (m *Machine) GetBreakpoint(id int) (Breakpoint, bool) {
+	c := m.ctl
+	c.debug.Lock()
+	defer c.debug.Unlock()
+	bp := c.findBreakpoint(id)
+	if bp == nil {
+		return Breakpoint{}, false
+	}
+	return *bp, true
+}
+
+// Breakpoints copy of all breakpoints, by ID order
+func (m *Machine) Breakpoints() []Breakpoint {
+	c := m.ctl
+	c.debug.Lock()
+	var list []Breakpoint
+	for _, bps := range c.breakpoints {
+		for _, bp := range bps {
+			list = append(list, *bp)
+		}
+	}
+	c.debug.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// Trace tracepoint at addr, call fn and continue, return breakpoint ID,
+// addr is ROM offset when banking, see TraceBank
+func (m *Machine) Trace(addr uint, fn func(m *Machine)) int {
+	return m.AddBreakpoint(Breakpoint{Addr: addr, Kind: BreakTrace, Action: fn})
+}
+
+// hitBreakpoints breakpoints at ROM offset reached,
+// Cond and Action are called without c.debug held, they may manage breakpoints
+func (m *Machine) hitBreakpoints(code uint) {
+	c := m.ctl
+	c.debug.Lock()
+	bps := c.breakpoints[code]
+	c.debug.Unlock()
+	for _, bp := range bps {
+		c.debug.Lock()
+		disabled := bp.Disabled
+		c.debug.Unlock()
+		if disabled || (bp.Cond != nil && !bp.Cond(m)) {
+			continue
+		}
+		c.debug.Lock()
+		bp.Hits++
+		trigger := bp.Hits > bp.Ignore
+		if trigger && bp.OneShot {
+			c.removeBreakpoint(bp)
+		}
+		c.debug.Unlock()
+		if !trigger {
+			continue
+		}
+		if bp.Action != nil {
+			bp.Action(m)
+		}
+		if bp.Kind == BreakStop {
+			m.Break()
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package asm_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+func Test_Breakpoint_Manager(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x0F,       // 0000: INC R7
+		0x80, 0xFD, // 0001: SJMP 0000
+	}
+	traces := 0
+	trace := m.Trace(0x01, func(m *asm.Machine) { traces++ })
+	stop := m.AddBreakpoint(asm.Breakpoint{
+		Addr:   0x01,
+		Cond:   func(m *asm.Machine) bool { return m.DATA[asm.R7]%2 == 0 },
+		Ignore: 1,
+	})
+
+	h := m.RunCycles(1000)
+	if h.Reason != asm.HaltBreakpoint || h.PC != 0x01 || m.DATA[asm.R7] != 4 || traces != 4 {
+		t.Errorf("conditional %s, R7 %d, traces %d", h, m.DATA[asm.R7], traces)
+	}
+	if bp, ok := m.GetBreakpoint(stop); !ok || bp.Hits != 2 {
+		t.Errorf("hits %d", bp.Hits)
+	}
+
+	m.EnableBreakpoint(stop, false)
+	if h := m.RunCycles(30); h.Reason != asm.HaltLimit {
+		t.Errorf("disabled %s", h)
+	}
+	m.EnableBreakpoint(stop, true)
+	if h := m.RunCycles(30); h.Reason != asm.HaltBreakpoint {
+		t.Errorf("enabled %s", h)
+	}
+	if !m.RemoveBreakpoint(stop) || m.RemoveBreakpoint(stop) {
+		t.Errorf("remove twice")
+	}
+
+	once := m.AddBreakpoint(asm.Breakpoint{Addr: 0x00, OneShot: true})
+	if h := m.RunCycles(30); h.Reason != asm.HaltBreakpoint || h.PC != 0x00 {
+		t.Errorf("one-shot %s", h)
+	}
+	if _, ok := m.GetBreakpoint(once); ok {
+		t.Errorf("one-shot not removed")
+	}
+	if h := m.RunCycles(30); h.Reason != asm.HaltLimit {
+		t.Errorf("after one-shot %s", h)
+	}
+
+	bps := m.Breakpoints()
+	if len(bps) != 1 || bps[0].ID != trace || bps[0].Kind != asm.BreakTrace {
+		t.Errorf("breakpoints %+v", bps)
+	}
+}
+
+func Test_Breakpoint_Single(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x74, 0x55, // 0000: MOV A, #0x55
+		0x80, 0xFE, // 0002: SJMP 0002
+	}
+	m.AddBreakpoint(asm.Breakpoint{Addr: 0x00})
+
+	err := m.Single()
+	if !errors.Is(err, asm.ErrBreak) || m.PC != 0x00 || m.SFR[asm.ACC] != 0x00 {
+		t.Fatalf("break %v, PC %04X A %02X", err, m.PC, m.SFR[asm.ACC])
+	}
+	if err := m.Single(); err != nil || m.PC != 0x02 || m.SFR[asm.ACC] != 0x55 {
+		t.Errorf("resume %v, PC %04X A %02X", err, m.PC, m.SFR[asm.ACC])
+	}
+}
+
+func Test_Breakpoint_WhileRunning(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	m := asm.NewMachine(time.Microsecond)
+	m.ROM = []byte{
+		0x0F,       // 0000: INC R7
+		0x80, 0xFD, // 0001: SJMP 0000
+	}
+	m.Start(ctx)
+	if err := m.WaitState(ctx, asm.StateRunning); err != nil {
+		t.Fatal(err)
+	}
+	for k := 0; k < 100; k++ {
+		id := m.Trace(0x01, func(m *asm.Machine) {})
+		m.EnableBreakpoint(id, false)
+		m.Breakpoints()
+		m.GetBreakpoint(id)
+		m.RemoveBreakpoint(id)
+	}
+	stop := m.AddBreakpoint(asm.Breakpoint{Addr: 0x01, OneShot: true})
+	if err := m.WaitState(ctx, asm.StatePaused); err != nil {
+		t.Fatal(err)
+	}
+	if h := m.LastHalt(); h.Reason != asm.HaltBreakpoint || h.PC != 0x01 {
+		t.Errorf("breakpoint %s", h)
+	}
+	if _, ok := m.GetBreakpoint(stop); ok {
+		t.Errorf("one-shot breakpoint kept")
+	}
+	m.Stop()
+	if err := m.WaitState(ctx, asm.StateStopped); err != nil {
+		t.Fatal(err)
+	}
+}
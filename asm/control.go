@@ -0,0 +1,345 @@
+package asm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// State run loop state
+type State int
+
+const (
+	// StateStopped run loop not started or exited
+	StateStopped State = iota
+	// StateRunning run loop executing instructions
+	StateRunning
+	// StatePaused run loop waiting for Resume
+	StatePaused
+)
+
+func (s State) String() string {
+	switch s {
+	case StateStopped:
+		return "Stopped"
+	case StateRunning:
+		return "Running"
+	}
+	return "Paused"
+}
+
+// control run loop state, shared by copies of machine
+type control struct {
+	mu      sync.Mutex
+	state   State         // state reached by run loop
+	want    State         // state requested by control methods
+	changed chan struct{} // closed on state change
+	wake    chan struct{} // wake run loop on request
+	done    chan struct{} // closed when run loop exited, nil if not started
+	halt    Halt          // last halt of run loop
+
+	batch time.Duration // real-time batch of virtual time, 0: one instruction each m.Frequency
+	drift time.Duration // virtual time ahead of wall-clock time in real-time mode
+
+	// breakpoints and watchpoints managed by other goroutines while running,
+	// lists are replaced on change, not modified in place
+	debug       sync.Mutex
+	breakpoints map[uint][]*Breakpoint // by ROM offset
+	nextBreakID int
+	watchpoints []*Watchpoint
+	nextWatchID int
+	watching    int32 // len(watchpoints), read atomically on memory access
+}
+
+func newControl() *control {
+	return &control{
+		changed:     make(chan struct{}),
+		wake:        make(chan struct{}, 1),
+		breakpoints: make(map[uint][]*Breakpoint),
+	}
+}
+
+// setState set state reached, c.mu locked
+func (c *control) setState(s State) {
+	if c.state == s {
+		return
+	}
+	c.state = s
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// request request state and wake run loop
+func (c *control) request(s State) {
+	c.want = s
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start start run loop in a new goroutine, one instruction each m.Frequency,
+// or in real-time mode by SetRealTime,
+// it is stopped by Stop or ctx done, do nothing if running or paused
+func (m *Machine) Start(ctx context.Context) {
+	c := m.ctl
+	c.mu.Lock()
+	for c.done != nil && c.want == StateStopped {
+		// previous run loop is exiting
+		done := c.done
+		c.mu.Unlock()
+		<-done
+		c.mu.Lock()
+	}
+	if c.done != nil {
+		c.mu.Unlock()
+		return
+	}
+	c.done = make(chan struct{})
+	c.want = StateRunning
+	c.setState(StateRunning)
+	c.mu.Unlock()
+	go m.loop(ctx)
+}
+
+// Pause pause running run loop
+func (m *Machine) Pause() {
+	c := m.ctl
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.want == StateRunning {
+		c.request(StatePaused)
+	}
+}
+
+// Resume resume paused run loop
+func (m *Machine) Resume() {
+	c := m.ctl
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.want == StatePaused {
+		c.request(StateRunning)
+	}
+}
+
+// Stop stop run loop, safe to call from Trace callback
+func (m *Machine) Stop() {
+	c := m.ctl
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done != nil {
+		c.request(StateStopped)
+	}
+}
+
+// State state of run loop
+func (m *Machine) State() State {
+	m.ctl.mu.Lock()
+	defer m.ctl.mu.Unlock()
+	return m.ctl.state
+}
+
+// LastHalt last halt of run loop, breakpoint pause or execution error stop
+func (m *Machine) LastHalt() Halt {
+	m.ctl.mu.Lock()
+	defer m.ctl.mu.Unlock()
+	return m.ctl.halt
+}
+
+// WaitState wait for run loop reach state s, or ctx done
+func (m *Machine) WaitState(ctx context.Context, s State) error {
+	c := m.ctl
+	for {
+		c.mu.Lock()
+		if c.state == s {
+			c.mu.Unlock()
+			return nil
+		}
+		changed := c.changed
+		c.mu.Unlock()
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// SetRealTime real-time mode, run loop execute batch of virtual time by crystal frequency
+// then sleep to keep virtual time locked to wall-clock time, 0 to disable
+func (m *Machine) SetRealTime(batch time.Duration) {
+	m.ctl.mu.Lock()
+	defer m.ctl.mu.Unlock()
+	m.ctl.batch = batch
+	select {
+	case m.ctl.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Drift virtual time ahead of wall-clock time in real-time mode, negative when host too slow,
+// measured before each batch
+func (m *Machine) Drift() time.Duration {
+	m.ctl.mu.Lock()
+	defer m.ctl.mu.Unlock()
+	return m.ctl.drift
+}
+
+// loop run loop, pause on breakpoint and watchpoint, stop on execution error
+func (m *Machine) loop(ctx context.Context) {
+	c := m.ctl
+	var (
+		ticker *time.Ticker
+		tick   <-chan time.Time
+		sleep  = time.NewTimer(time.Hour)
+		// real-time reference, reset on start, resume and mode change
+		wallStart time.Time
+		virtStart time.Duration
+		synced    bool
+	)
+	sleep.Stop()
+	defer func() {
+		if ticker != nil {
+			ticker.Stop()
+		}
+	}()
+	defer func() {
+		m.idleEnd = 0
+		c.mu.Lock()
+		c.want = StateStopped
+		c.setState(StateStopped)
+		close(c.done)
+		c.done = nil
+		c.mu.Unlock()
+	}()
+
+	for {
+		c.mu.Lock()
+		want := c.want
+		batch := c.batch
+		c.setState(want)
+		c.mu.Unlock()
+
+		switch want {
+		case StateStopped:
+			return
+		case StatePaused:
+			synced = false
+			select {
+			case <-c.wake:
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+
+		if batch == 0 {
+			synced = false
+			if ticker == nil {
+				ticker = time.NewTicker(m.Frequency)
+				tick = ticker.C
+			}
+			select {
+			case <-tick:
+			case <-c.wake:
+				continue
+			case <-ctx.Done():
+				return
+			}
+			m.loopStep()
+			continue
+		}
+
+		if ticker != nil {
+			ticker.Stop()
+			ticker, tick = nil, nil
+		}
+		if m.PowerDown() {
+			// oscillator stopped, virtual time frozen, sample wakeup pins once a batch
+			synced = false
+			if m.loopStep() && m.PowerDown() {
+				sleep.Reset(batch)
+				select {
+				case <-sleep.C:
+				case <-c.wake:
+					if !sleep.Stop() {
+						<-sleep.C
+					}
+				case <-ctx.Done():
+					return
+				}
+			}
+			continue
+		}
+		if !synced {
+			wallStart, virtStart, synced = time.Now(), m.Time(), true
+		}
+		drift := (m.Time() - virtStart) - time.Since(wallStart)
+		c.mu.Lock()
+		c.drift = drift
+		c.mu.Unlock()
+		if drift > 0 {
+			// ahead, sleep until wall-clock time catch up
+			sleep.Reset(drift)
+			select {
+			case <-sleep.C:
+			case <-c.wake:
+				if !sleep.Stop() {
+					<-sleep.C
+				}
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		// run one batch, idle fast-forward not beyond it,
+		// end it early on power-down and control request
+		n := m.TimeToCycles(batch)
+		if n == 0 {
+			n = 1
+		}
+		end := m.Cycles + n
+		m.idleEnd = end
+	steps:
+		for m.Cycles < end && !m.PowerDown() && m.loopStep() {
+			select {
+			case <-c.wake:
+				break steps
+			case <-ctx.Done():
+				return
+			default:
+			}
+		}
+		m.idleEnd = 0
+	}
+}
+
+// loopStep step in run loop, false if halted
+func (m *Machine) loopStep() bool {
+	c := m.ctl
+	if err := m.step(); err != nil {
+		m.watchHit = nil
+		c.mu.Lock()
+		c.halt = m.haltOf(err)
+		c.want = StateStopped
+		c.mu.Unlock()
+		return false
+	}
+	if hit := m.takeWatchHit(); hit != nil || m.breakReq {
+		h := m.haltAt(HaltBreakpoint, m.PC)
+		if hit != nil {
+			h = m.haltAt(HaltWatchpoint, m.PC)
+			h.Watch = hit
+		}
+		m.breakReq = false
+		c.mu.Lock()
+		c.halt = h
+		if c.want == StateRunning {
+			c.want = StatePaused
+		}
+		c.mu.Unlock()
+		return false
+	}
+	return true
+}
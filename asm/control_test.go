@@ -0,0 +1,183 @@
+package asm_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+func Test_Control_Lifecycle(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	m := asm.NewMachine(time.Microsecond)
+	m.ROM = []byte{
+		0x0F,       // 0000: INC R7
+		0x80, 0xFD, // 0001: SJMP 0000
+	}
+	hits := 0
+	m.Trace(0x01, func(m *asm.Machine) {
+		hits++
+		if hits == 3 {
+			m.Break()
+		}
+	})
+
+	m.Start(ctx)
+	m.Start(ctx)
+	if err := m.WaitState(ctx, asm.StatePaused); err != nil {
+		t.Fatal(err)
+	}
+	if h := m.LastHalt(); h.Reason != asm.HaltBreakpoint || h.PC != 0x01 || m.DATA[asm.R7] != 3 {
+		t.Errorf("breakpoint %s, R7 %d", h, m.DATA[asm.R7])
+	}
+
+	m.Resume()
+	if err := m.WaitState(ctx, asm.StateRunning); err != nil {
+		t.Fatal(err)
+	}
+	m.Pause()
+	m.Pause()
+	if err := m.WaitState(ctx, asm.StatePaused); err != nil {
+		t.Fatal(err)
+	}
+	r7 := m.DATA[asm.R7]
+	time.Sleep(10 * time.Millisecond)
+	if m.DATA[asm.R7] != r7 {
+		t.Errorf("run while paused")
+	}
+
+	// stop from many goroutines
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Stop()
+		}()
+	}
+	wg.Wait()
+	if err := m.WaitState(ctx, asm.StateStopped); err != nil {
+		t.Fatal(err)
+	}
+	m.Stop()
+	m.Resume()
+	if m.State() != asm.StateStopped {
+		t.Errorf("state %s after stop", m.State())
+	}
+
+	// restart, stopped by context
+	runCtx, runCancel := context.WithCancel(ctx)
+	m.Start(runCtx)
+	runCancel()
+	if err := m.WaitState(ctx, asm.StateStopped); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Control_Illegal(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	m := asm.NewMachine(time.Microsecond)
+	m.ROM = []byte{
+		0x00, // 0000: NOP
+		0xA5, // 0001: reserved opcode
+	}
+	m.Start(ctx)
+	if err := m.WaitState(ctx, asm.StateStopped); err != nil {
+		t.Fatal(err)
+	}
+	if h := m.LastHalt(); h.Reason != asm.HaltIllegal || h.PC != 0x01 {
+		t.Errorf("halt %s", h)
+	}
+}
+
+func Test_Control_RealTime(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x80, 0xFE, // 0000: SJMP 0000
+	}
+	m.Crystal = 1200000
+	m.SetRealTime(2 * time.Millisecond)
+	start := time.Now()
+	m.Start(ctx)
+	time.Sleep(100 * time.Millisecond)
+	m.Pause()
+	if err := m.WaitState(ctx, asm.StatePaused); err != nil {
+		t.Fatal(err)
+	}
+	wall := time.Since(start)
+	// 1.2MHz crystal, 10us per machine cycle,
+	// never ahead by more than a batch, slow host only fall behind
+	if m.Time() > wall+2*time.Millisecond || m.Time() < wall/4 {
+		t.Errorf("virtual time %s, wall-clock time %s", m.Time(), wall)
+	}
+	if d := m.Drift(); d > 2*time.Millisecond || d < -wall/2 {
+		t.Errorf("drift %s", d)
+	}
+	m.Stop()
+	if err := m.WaitState(ctx, asm.StateStopped); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_Control_RealTimePowerDown(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x75, 0x87, 0x02, // 0000: MOV PCON, #0x02, power-down
+		0x80, 0xFE, // 0003: SJMP 0003
+	}
+	m.SetRealTime(time.Second)
+	m.Start(ctx)
+	if err := m.WaitState(ctx, asm.StateRunning); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	m.Pause()
+	if err := m.WaitState(ctx, asm.StatePaused); err != nil {
+		t.Fatal(err)
+	}
+	if !m.PowerDown() || m.Cycles != 2 {
+		t.Errorf("power-down PCON %02X at cycle %d", m.SFR[asm.PCON], m.Cycles)
+	}
+	m.Resume()
+	start := time.Now()
+	m.Stop()
+	if err := m.WaitState(ctx, asm.StateStopped); err != nil {
+		t.Fatal(err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("stop in power-down took %s", d)
+	}
+}
+
+func Test_Control_RealTimeStopInBatch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x80, 0xFE, // 0000: SJMP 0000
+	}
+	// one batch is far longer than the test
+	m.SetRealTime(time.Hour)
+	m.Start(ctx)
+	time.Sleep(10 * time.Millisecond)
+	m.Stop()
+	if err := m.WaitState(ctx, asm.StateStopped); err != nil {
+		t.Fatal(err)
+	}
+	if m.Time() > time.Minute {
+		t.Errorf("batch not ended by Stop, virtual time %s", m.Time())
+	}
+}
@@ -0,0 +1,64 @@
+package asm
+
+// call and return opcodes
+const (
+	opLCALL uint8 = 0x12
+	opRET   uint8 = 0x22
+	opRETI  uint8 = 0x32
+)
+
+// isCall LCALL or ACALL
+func isCall(op uint8) bool {
+	return op == opLCALL || op&0x1F == 0x11
+}
+
+// inISR interrupt vectored since isr0, not returned yet
+func (m *Machine) inISR(isr0 uint8) bool {
+	return m.isrActive&^isr0 != 0
+}
+
+// StepOver execute one instruction, run through LCALL and ACALL until return to the same stack depth,
+// interrupt vectored in the middle is run through too
+func (m *Machine) StepOver() Halt {
+	isr0 := m.isrActive
+	op := m.ReadCODE(m.PC)
+	if !isCall(op) {
+		return m.run(noLimit, noLimit, func(m *Machine) bool { return !m.inISR(isr0) })
+	}
+	i, _ := FindINS(op)
+	ret := m.PC + uint(i.Bytes)
+	sp0 := m.SFR[SP]
+	return m.run(noLimit, noLimit, func(m *Machine) bool {
+		return m.PC == ret && m.SFR[SP] == sp0 && !m.inISR(isr0)
+	})
+}
+
+// StepOut run until current function or interrupt service routine return by RET or RETI
+func (m *Machine) StepOut() Halt {
+	isr0 := m.isrActive
+	sp0 := m.SFR[SP]
+	op := m.ReadCODE(m.PC)
+	return m.run(noLimit, noLimit, func(m *Machine) bool {
+		ret := op == opRET || op == opRETI
+		op = m.ReadCODE(m.PC)
+		return ret && m.SFR[SP] < sp0 && !m.inISR(isr0)
+	})
+}
+
+// RunTo run until PC reach addr in any code bank, not inside interrupt vectored in the middle
+func (m *Machine) RunTo(addr uint) Halt {
+	isr0 := m.isrActive
+	return m.run(noLimit, noLimit, func(m *Machine) bool {
+		return m.PC == addr && !m.inISR(isr0)
+	})
+}
+
+// RunToBank run until PC reach bank:address, not inside interrupt vectored in the middle,
+// bank is ignored in common area
+func (m *Machine) RunToBank(bank uint, addr uint) Halt {
+	isr0 := m.isrActive
+	code := m.CodeAddr(bank, addr)
+	return m.run(noLimit, noLimit, func(m *Machine) bool {
+		return m.atCode(code) && !m.inISR(isr0)
+	})
+}
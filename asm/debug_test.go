@@ -0,0 +1,73 @@
+package asm_test
+
+import (
+	"testing"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+func newDebugMachine() *asm.Machine {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = make([]byte, 0x60)
+	copy(m.ROM[0x00:], []byte{0x02, 0x00, 0x30}) // 0000: LJMP 0030
+	copy(m.ROM[0x0B:], []byte{
+		0x05, 0x40, // 000B: INC 0x40
+		0x32, // 000D: RETI
+	})
+	copy(m.ROM[0x30:], []byte{
+		0x75, 0x89, 0x02, // 0030: MOV TMOD, #0x02, timer 0 mode 2
+		0x75, 0x8C, 0xF6, // 0033: MOV TH0, #0xF6, every 10 cycles
+		0x75, 0xA8, 0x82, // 0036: MOV IE, #0x82, EA ET0
+		0xD2, 0x8C, // 0039: SETB TR0
+		0x00,             // 003B: NOP
+		0x12, 0x00, 0x50, // 003C: LCALL 0050
+		0x05, 0x41, // 003F: INC 0x41
+		0x80, 0xFE, // 0041: SJMP 0041
+	})
+	copy(m.ROM[0x50:], []byte{
+		0x7F, 0x14, // 0050: MOV R7, #20
+		0xDF, 0xFE, // 0052: DJNZ R7, 0052
+		0x22, // 0054: RET
+	})
+	m.WriteDATA(asm.TL0, 0xF6)
+	return m
+}
+
+func Test_Debug_StepOver(t *testing.T) {
+	m := newDebugMachine()
+	if h := m.RunTo(0x3C); h.Reason != asm.HaltCondition || m.InterruptLevel() != -1 {
+		t.Fatalf("RunTo %s, interrupt level %d", h, m.InterruptLevel())
+	}
+	sp := m.SFR[asm.SP]
+	if h := m.StepOver(); h.Reason != asm.HaltCondition || m.PC != 0x3F || m.SFR[asm.SP] != sp {
+		t.Errorf("step over LCALL %s, SP %02X", h, m.SFR[asm.SP])
+	}
+	if m.DATA[0x40] == 0 {
+		t.Errorf("interrupt not served while stepping over")
+	}
+	for i := 0; i < 20; i++ {
+		// SJMP 0041 stay in place, interrupts run through
+		if h := m.StepOver(); m.PC != 0x41 || m.InterruptLevel() != -1 {
+			t.Fatalf("step over %s, interrupt level %d", h, m.InterruptLevel())
+		}
+	}
+	if m.DATA[0x41] != 1 {
+		t.Errorf("DATA[41] %d", m.DATA[0x41])
+	}
+}
+
+func Test_Debug_StepOut(t *testing.T) {
+	m := newDebugMachine()
+	m.RunTo(0x52)
+	if h := m.StepOut(); h.Reason != asm.HaltCondition || m.PC != 0x3F || m.InterruptLevel() != -1 {
+		t.Errorf("step out of function %s, interrupt level %d", h, m.InterruptLevel())
+	}
+
+	m.RunUntilPC(0x0B)
+	if m.InterruptLevel() != 0 {
+		t.Fatalf("not in ISR")
+	}
+	if h := m.StepOut(); h.Reason != asm.HaltCondition || m.PC < 0x30 || m.InterruptLevel() != -1 {
+		t.Errorf("step out of ISR %s, interrupt level %d", h, m.InterruptLevel())
+	}
+}
@@ -0,0 +1,65 @@
+package asm
+
+import (
+	"errors"
+	"fmt"
+)
+
+// execution errors, wrapped in ExecError
+var (
+	// ErrIllegalOpcode opcode not defined, 0xA5
+	ErrIllegalOpcode = errors.New("illegal opcode")
+	// ErrPCOutsideROM PC outside ROM
+	ErrPCOutsideROM = errors.New("PC outside ROM")
+	// ErrFetchPastROM operand fetch past the end of ROM
+	ErrFetchPastROM = errors.New("operand fetch past the end of ROM")
+	// ErrStackOverflow push beyond internal RAM
+	ErrStackOverflow = errors.New("stack overflow")
+	// ErrBreak instruction not executed, stopped by breakpoint
+	ErrBreak = errors.New("break")
+	// ErrWatch instruction executed, stopped by watchpoint with Halt, see WatchError
+	ErrWatch = errors.New("watchpoint")
+)
+
+// ExecError error of instruction at PC, Err is one of Err* or error raised by peripheral
+type ExecError struct {
+	PC     uint // PC of the instruction
+	Opcode uint8
+	// NoOpcode no opcode fetched, PC outside ROM or CPU in idle and power-down
+	NoOpcode bool
+	Err      error
+}
+
+func (e *ExecError) Error() string {
+	if e.NoOpcode {
+		return fmt.Sprintf("PC:%04X %s", e.PC, e.Err)
+	}
+	return fmt.Sprintf("PC:%04X opcode:%02X %s", e.PC, e.Opcode, e.Err)
+}
+
+// Unwrap underlying error
+func (e *ExecError) Unwrap() error {
+	return e.Err
+}
+
+// WatchError watchpoint hit with Halt, wrapped in ExecError, unwrap to ErrWatch
+type WatchError struct {
+	Hit WatchHit
+}
+
+func (e *WatchError) Error() string {
+	h := e.Hit
+	return fmt.Sprintf("%s %s %s %04X", ErrWatch, h.Space, h.Kind, h.Addr)
+}
+
+// Unwrap ErrWatch
+func (e *WatchError) Unwrap() error {
+	return ErrWatch
+}
+
+// Fault raise error by peripheral or hook, returned by current step
+func (m *Machine) Fault(err error) {
+	if m.fault == nil {
+		m.fault = err
+	}
+}
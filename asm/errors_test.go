@@ -0,0 +1,75 @@
+package asm_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+func Test_Exec_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		rom    []byte
+		steps  int
+		err    error
+		pc     uint
+		opcode uint8
+	}{
+		{"illegal", []byte{0x00, 0xA5}, 2, asm.ErrIllegalOpcode, 0x01, 0xA5},
+		{"outside ROM", []byte{0x00}, 2, asm.ErrPCOutsideROM, 0x01, 0x00},
+		{"fetch past ROM", []byte{0x00, 0x02, 0x00}, 2, asm.ErrFetchPastROM, 0x01, 0x02},
+		// SP wrap around
+		{"stack overflow", []byte{0x75, 0x81, 0xFF, 0xC0, 0xE0}, 2, asm.ErrStackOverflow, 0x03, 0xC0},
+	}
+	for _, tt := range tests {
+		m := asm.NewMachine(asm.Frequency1MHz)
+		m.ROM = tt.rom
+		var err error
+		for i := 0; i < tt.steps && err == nil; i++ {
+			err = m.Single()
+		}
+		var e *asm.ExecError
+		if !errors.Is(err, tt.err) || !errors.As(err, &e) {
+			t.Errorf("%s: error %v", tt.name, err)
+			continue
+		}
+		if e.PC != tt.pc || e.Opcode != tt.opcode || e.NoOpcode != (tt.err == asm.ErrPCOutsideROM) {
+			t.Errorf("%s: PC %04X opcode %02X", tt.name, e.PC, e.Opcode)
+		}
+	}
+}
+
+var errBusFault = errors.New("bus fault")
+
+type faulty struct {
+	asm.BasePeripheral
+}
+
+func (faulty) Tick(m *asm.Machine, cycles uint64) {
+	if m.Cycles >= 10 {
+		m.Fault(errBusFault)
+	}
+}
+
+func Test_Exec_PeripheralError(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x80, 0xFE, // 0000: SJMP 0000
+	}
+	m.Attach(faulty{})
+	h := m.RunCycles(100)
+	if h.Reason != asm.HaltError || !errors.Is(h.Err, errBusFault) || h.PC != 0x00 || m.Cycles != 10 {
+		t.Errorf("halt %s, cycles %d", h, m.Cycles)
+	}
+}
+
+func Test_Exec_StackOverflow8051(t *testing.T) {
+	// 8051 has 128 bytes internal RAM
+	m := asm.NewMachine(asm.Frequency1MHz, asm.Variant8051)
+	m.ROM = []byte{0x75, 0x81, 0x7F, 0xC0, 0xE0} // MOV SP, #0x7F; PUSH ACC
+	m.Single()
+	if err := m.Single(); !errors.Is(err, asm.ErrStackOverflow) {
+		t.Errorf("error %v", err)
+	}
+}
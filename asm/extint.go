@@ -0,0 +1,40 @@
+package asm
+
+// extInt external interrupt INT0 INT1, pins sampled once per machine cycle
+type extInt struct {
+	pin [2]bool // last sampled level of INT0 INT1 pin
+}
+
+func newExtInt() *extInt {
+	return &extInt{}
+}
+
+func (e *extInt) tick(m *Machine, cycles uint64) {
+	var (
+		it   = [2]uint8{IT0, IT1}
+		flag = [2]uint8{IE0, IE1}
+	)
+	for x := uint(0); x < 2; x++ {
+		// INT0: P3.2, INT1: P3.3
+		level := m.pin(P3, 2+x)
+		if m.bit(it[x]) {
+			// falling edge triggered, IEx cleared by hardware when vectoring
+			if e.pin[x] && !level {
+				m.setBit(flag[x], true)
+			}
+		} else {
+			// low level triggered, IEx follow the pin
+			m.setBit(flag[x], !level)
+		}
+		e.pin[x] = level
+	}
+}
+
+// next pins are driven by host, nothing scheduled
+func (e *extInt) next(m *Machine) uint64 {
+	return 0
+}
+
+func (e *extInt) reset(m *Machine) {
+	e.pin = [2]bool{true, true}
+}
@@ -0,0 +1,60 @@
+package asm_test
+
+import (
+	"testing"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+func Test_ExtInt_Edge_Level(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = make([]byte, 0x40)
+	copy(m.ROM[0x00:], []byte{0x02, 0x00, 0x30}) // 0000: LJMP 0030
+	copy(m.ROM[0x03:], []byte{
+		0x05, 0x40, // 0003: INC 0x40
+		0x32, // 0005: RETI
+	})
+	copy(m.ROM[0x30:], []byte{
+		0x75, 0xB0, 0xFF, // 0030: MOV P3, #0xFF
+		0xD2, 0x88, // 0033: SETB IT0
+		0x75, 0xA8, 0x81, // 0035: MOV IE, #0x81, EA EX0
+		0x80, 0xFE, // 0038: SJMP 0038
+	})
+	run := func(n int) {
+		for i := 0; i < n; i++ {
+			m.Single()
+		}
+	}
+
+	run(10)
+	m.DrivePin(asm.P3, 2, asm.DriveLow)
+	run(10)
+	if m.DATA[0x40] != 1 {
+		t.Errorf("falling edge interrupt %d times", m.DATA[0x40])
+	}
+	if m.ReadBit(asm.IE0) {
+		t.Errorf("IE0 should be cleared by hardware in edge mode")
+	}
+
+	m.DrivePin(asm.P3, 2, asm.DriveRelease)
+	run(10)
+	m.DrivePin(asm.P3, 2, asm.DriveLow)
+	run(10)
+	if m.DATA[0x40] != 2 {
+		t.Errorf("second falling edge interrupt %d times", m.DATA[0x40])
+	}
+
+	// low level keep requesting
+	m.WriteBit(asm.IT0, false)
+	run(20)
+	if m.DATA[0x40] < 5 {
+		t.Errorf("low level interrupt %d times", m.DATA[0x40])
+	}
+	m.DrivePin(asm.P3, 2, asm.DriveRelease)
+	run(4)
+	count := m.DATA[0x40]
+	run(20)
+	if m.DATA[0x40] != count || m.ReadBit(asm.IE0) {
+		t.Errorf("high level should not request, IE0 %t", m.ReadBit(asm.IE0))
+	}
+}
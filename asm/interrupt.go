@@ -0,0 +1,145 @@
+package asm
+
+// Interrupt 8051 interrupt source
+type Interrupt struct {
+	Name   string
+	Vector uint    // CODE address of interrupt service routine
+	Flags  []uint8 // request flag bit addresses, any of them set to request
+	// Enable enable bit, like ByBit(EX0), nil: enabled by EA only
+	Enable func(m *Machine) bool
+	// Priority high priority bit, like ByBit(PX0), nil: always low priority
+	Priority func(m *Machine) bool
+	// Request request line of flags not bit-addressable
+	Request func(m *Machine) bool
+	// Ack called when vectoring, clear the flags which cleared by hardware
+	Ack func(m *Machine)
+}
+
+// interruptList interrupt sources, in polling order
+var interruptList = []Interrupt{
+	{Name: "INT0", Vector: 0x0003, Enable: ByBit(EX0), Priority: ByBit(PX0), Flags: []uint8{IE0}, Ack: func(m *Machine) {
+		// edge triggered request flag cleared by hardware
+		if m.ReadBit(IT0) {
+			m.WriteBit(IE0, false)
+		}
+	}},
+	{Name: "Timer0", Vector: 0x000B, Enable: ByBit(ET0), Priority: ByBit(PT0), Flags: []uint8{TF0}, Ack: func(m *Machine) {
+		m.WriteBit(TF0, false)
+	}},
+	{Name: "INT1", Vector: 0x0013, Enable: ByBit(EX1), Priority: ByBit(PX1), Flags: []uint8{IE1}, Ack: func(m *Machine) {
+		if m.ReadBit(IT1) {
+			m.WriteBit(IE1, false)
+		}
+	}},
+	{Name: "Timer1", Vector: 0x001B, Enable: ByBit(ET1), Priority: ByBit(PT1), Flags: []uint8{TF1}, Ack: func(m *Machine) {
+		m.WriteBit(TF1, false)
+	}},
+	// RI TI cleared by software
+	{Name: "Serial", Vector: 0x0023, Enable: ByBit(ES), Priority: ByBit(PS), Flags: []uint8{RI, TI}},
+}
+
+// interruptTimer2 8052 timer 2 interrupt, TF2 EXF2 cleared by software
+var interruptTimer2 = Interrupt{Name: "Timer2", Vector: 0x002B, Enable: ByBit(ET2), Priority: ByBit(PT2), Flags: []uint8{TF2, EXF2}}
+
+// ByBit bit address as interrupt enable or priority
+func ByBit(bit uint8) func(m *Machine) bool {
+	return func(m *Machine) bool { return m.ReadBit(bit) }
+}
+
+// enabled interrupt enabled by its enable bit
+func (i *Interrupt) enabled(m *Machine) bool {
+	return i.Enable == nil || i.Enable(m)
+}
+
+// high interrupt in high priority
+func (i *Interrupt) high(m *Machine) bool {
+	return i.Priority != nil && i.Priority(m)
+}
+
+// interrupt priority level in progress
+const (
+	isrLow  uint8 = 1 << 0
+	isrHigh uint8 = 1 << 1
+)
+
+// Pending interrupt has request flag set or request line active
+func (i *Interrupt) Pending(m *Machine) bool {
+	for _, f := range i.Flags {
+		if m.ReadBit(f) {
+			return true
+		}
+	}
+	return i.Request != nil && i.Request(m)
+}
+
+// InterruptLevel priority level of interrupt in progress, -1: none, 0: low, 1: high
+func (m *Machine) InterruptLevel() int {
+	switch {
+	case m.isrActive&isrHigh != 0:
+		return 1
+	case m.isrActive&isrLow != 0:
+		return 0
+	}
+	return -1
+}
+
+// holdInterrupt at least one more instruction is executed before any interrupt is vectored,
+// after RETI or any write to IE/IP
+func (m *Machine) holdInterrupt() {
+	m.isrHold = true
+}
+
+// reti restore interrupt priority state in progress
+func (m *Machine) reti() {
+	if m.isrActive&isrHigh != 0 {
+		m.isrActive &^= isrHigh
+	} else {
+		m.isrActive &^= isrLow
+	}
+	m.holdInterrupt()
+}
+
+// pollInterrupt after each instruction, vector to the pending interrupt if any,
+// return machine cycles used by the hardware LCALL
+func (m *Machine) pollInterrupt() uint64 {
+	if m.isrHold {
+		m.isrHold = false
+		return 0
+	}
+	if !m.ReadBit(EA) || m.isrActive&isrHigh != 0 {
+		return 0
+	}
+
+	var found *Interrupt
+	for k := range m.interrupts {
+		i := &m.interrupts[k]
+		if !i.enabled(m) || !i.Pending(m) {
+			continue
+		}
+		if i.high(m) {
+			// high priority interrupt is served first
+			found = i
+			break
+		}
+		if found == nil && m.isrActive == 0 {
+			found = i
+		}
+	}
+	if found == nil {
+		return 0
+	}
+
+	if found.high(m) {
+		m.isrActive |= isrHigh
+	} else {
+		m.isrActive |= isrLow
+	}
+	if found.Ack != nil {
+		found.Ack(m)
+	}
+	m.wakeup()
+	m.push(uint8(m.PC))
+	m.push(uint8(m.PC >> 8))
+	m.PC = found.Vector
+	return 2
+}
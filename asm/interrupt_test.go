@@ -0,0 +1,54 @@
+package asm_test
+
+import (
+	"testing"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+func Test_Interrupt_Priority(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = make([]byte, 0x40)
+	copy(m.ROM[0x00:], []byte{0x02, 0x00, 0x30}) // 0000: LJMP 0030
+	copy(m.ROM[0x0B:], []byte{
+		0xD2, 0x8B, // 000B: SETB IE1, request high priority INT1
+		0xD2, 0x8D, // 000D: SETB TF0, request low priority timer 0 again
+		0x32, // 000F: RETI
+	})
+	copy(m.ROM[0x13:], []byte{
+		0x05, 0x40, // 0013: INC 0x40
+		0x32, // 0015: RETI
+	})
+	copy(m.ROM[0x30:], []byte{
+		0x75, 0x81, 0x60, // 0030: MOV SP, #0x60
+		0x75, 0xB8, 0x04, // 0033: MOV IP, #0x04, INT1 high priority
+		0x75, 0x88, 0x24, // 0036: MOV TCON, #0x24, TF0 IT1
+		0x75, 0xA8, 0x86, // 0039: MOV IE, #0x86, EA EX1 ET0
+		0x00,       // 003C: NOP, one more instruction after IE write
+		0x80, 0xFE, // 003D: SJMP 003D
+	})
+
+	want := []uint{
+		0x0000, 0x0030, 0x0033, 0x0036, 0x0039, 0x003C,
+		0x000B, 0x0013, 0x0015, 0x000D, 0x000F, 0x003D,
+		0x000B, 0x0013,
+	}
+	for k, pc := range want {
+		if m.PC != pc {
+			t.Fatalf("step %d: PC %04X != %04X", k, m.PC, pc)
+		}
+		if k == 8 && m.InterruptLevel() != 1 {
+			t.Errorf("step %d: interrupt level %d in INT1", k, m.InterruptLevel())
+		}
+		if k == 10 && m.InterruptLevel() != 0 {
+			t.Errorf("step %d: interrupt level %d in timer 0", k, m.InterruptLevel())
+		}
+		m.Single()
+	}
+	if m.DATA[0x40] != 2 {
+		t.Errorf("INT1 count %d", m.DATA[0x40])
+	}
+	if m.ReadBit(asm.TF0) {
+		t.Errorf("TF0 should be cleared by hardware")
+	}
+}
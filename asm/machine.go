@@ -2,6 +2,7 @@ package asm
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -16,147 +17,409 @@ const (
 	Frequency1MHz = time.Duration(int(float32(Frequency1Hz / 1000000)))
 )
 
-// BreakePoint Trace break point
-type BreakePoint struct {
-	Addr uint
-	Func func(m *Machine)
+const (
+	// Crystal11_0592MHz 11.0592MHz crystal, standard UART baud rate
+	Crystal11_0592MHz = 11059200
+	// Crystal12MHz 12MHz crystal, 1us machine cycle on classic core
+	Crystal12MHz = 12000000
+	// Crystal24MHz 24MHz crystal
+	Crystal24MHz = 24000000
+)
+
+// peripheral on-chip device, run by machine cycles
+type peripheral interface {
+	tick(m *Machine, cycles uint64)
+	// next machine cycles until next event may request interrupt, 0: none scheduled
+	next(m *Machine) uint64
+	// reset internal state to reset state, SFR already reset
+	reset(m *Machine)
 }
 
 // Machine 8051 microchip
 type Machine struct {
-	mainTick *time.Ticker
-	exitCh   chan int
-	isrCh    chan int
-
-	DATA         [0x100]byte   // RAM: DATA Range
-	XDATA        [0x10000]byte // RAM: XDATA Range
-	ROM          []byte        // ROM: CODE Range
-	PC           uint          // PC: program counter
-	brakepoints  map[uint][]func(m *Machine)
+	ctl *control // run loop state
+
+	DATA         [0x100]byte   // RAM: DATA Range, 0x80~0xFF IDATA only by indirect addressing
+	SFR          [0x100]byte   // SFR: Special Function Registers, 0x80~0xFF only by direct addressing
+	XDATA        [0x10000]byte // RAM: XDATA Range, unmapped by device
+	xdataDevices []xdataDevice
+	ROM          []byte   // ROM: CODE Range
+	banking      *Banking // code banking, nil for flat ROM
+	PC           uint     // PC: program counter
 	regDefines   []Register
 	insHookDATAR map[uint8][]func(m *Machine, val uint8)
 	insHookDATAW map[uint8][]func(m *Machine, old uint8, new uint8)
 	Frequency    time.Duration
+
+	Cycles         uint64 // machine cycles since start
+	Crystal        uint   // crystal frequency in Hz
+	ClocksPerCycle uint   // clocks per machine cycle, 12 on classic core
+	Variant        Variant
+
+	interrupts []Interrupt
+	isrActive  uint8 // interrupt priority level in progress
+	isrHold    bool  // hold interrupt for one more instruction
+
+	peripherals []peripheral
+	timer       *timer01
+	timer2      *timer2 // 8052 only
+	uart        *uart
+	ports       *ports
+	extInt      *extInt
+	// SFR read and write handled by peripheral instead of stored
+	sfrRead  map[uint8]func(m *Machine) uint8
+	sfrWrite map[uint8]func(m *Machine, val uint8)
+	rmw      bool // read-modify-write instruction reading, port read latch
+
+	fault    error  // error raised in this step
+	breakReq bool   // Break called
+	resume   bool   // resume from breakpoint, not call it again
+	idleEnd  uint64 // idle fast-forward not beyond this cycles, 0: no limit
+
+	insPC     uint      // PC of executing instruction
+	executing bool      // instruction executing, memory access watched
+	watchHit  *WatchHit // watchpoint hit to halt in this step
+	recorder  *TraceRecorder
+
+	sfrReset  map[uint8]uint8 // SFR reset values which are not zero
+	resets    uint            // count of resets
+	LastReset ResetCause      // cause of last reset
 }
 
-// NewMachine Create 8051 machine
-func NewMachine(f time.Duration) *Machine {
+// NewMachine Create 8051 machine, by variant profile,
+// flat 8051 as before variants if not given: 256 bytes internal RAM,
+// 64K on-chip CODE and XDATA without external bus, pass Variant8051 for the real part
+func NewMachine(f time.Duration, variant ...Variant) *Machine {
+	v := Variant8051
+	v.IRAMSize = 0x100
+	v.ROMSize = 0x10000
+	v.XRAMSize = 0x10000
+	if len(variant) > 0 {
+		v = variant[0]
+	}
 	m := &Machine{}
-	m.brakepoints = make(map[uint][]func(m *Machine))
+	m.Variant = v
+	m.ctl = newControl()
 	m.insHookDATAR = make(map[uint8][]func(m *Machine, val uint8))
 	m.insHookDATAW = make(map[uint8][]func(m *Machine, old uint8, val uint8))
 	m.Frequency = f
-	m.regDefines = regList
-
-	m.insideHookDATAWrite(FindRegByName("P1", regList).Addr, func(m *Machine, old uint8, new uint8) {
-		fmt.Printf("DATA W %02X : old(%02X) new(%02X)\n", P1, old, new)
-	})
-	m.insideHookDATAWrite(FindRegByName("P0", regList).Addr, func(m *Machine, old uint8, new uint8) {
-		fmt.Printf("DATA W %02X : old(%02X) new(%02X)\n", P0, old, new)
-	})
-	// m.insideHookDATAWrite(R0, func(m *Machine, old uint8, new uint8) {
-	// 	log.Printf("PC(%04X) DATA W %02X : old(%02X) new(%02X)\n", m.PC, R0, old, new)
-	// })
+	m.Crystal = v.Crystal
+	if m.Crystal == 0 {
+		m.Crystal = Crystal12MHz
+	}
+	m.ClocksPerCycle = v.ClocksPerCycle
+	if m.ClocksPerCycle == 0 {
+		m.ClocksPerCycle = 12
+	}
+	m.regDefines = append([]Register{}, v.SFRs...)
+	m.interrupts = append([]Interrupt{}, v.Interrupts...)
+	m.sfrReset = map[uint8]uint8{}
+	for addr, val := range v.ResetValues {
+		m.sfrReset[addr] = val
+	}
+	m.timer = newTimer01()
+	m.uart = newUART()
+	m.extInt = newExtInt()
+	m.peripherals = []peripheral{m.extInt, m.timer, m.uart}
+	m.ports = newPorts()
+	m.sfrRead = map[uint8]func(m *Machine) uint8{}
+	m.sfrWrite = map[uint8]func(m *Machine, val uint8){
+		SBUF: m.uart.writeSBUF,
+	}
+	for k, addr := range portAddr {
+		m.sfrRead[addr] = m.ports.read(k)
+		m.sfrWrite[addr] = m.ports.write(k)
+	}
+
+	if v.Timer2 {
+		m.timer2 = newTimer2()
+		m.peripherals = append(m.peripherals, m.timer2)
+	}
+
+	m.insideHookDATAWrite(IE, func(m *Machine, old uint8, new uint8) { m.holdInterrupt() })
+	m.insideHookDATAWrite(IP, func(m *Machine, old uint8, new uint8) { m.holdInterrupt() })
+	m.ResetBy(ResetPowerOn)
 	return m
 }
 
-// DumpFakeCode dump asm fakecode
+// DumpFakeCode dump asm fakecode, banked code as "bank:address"
 func (m Machine) DumpFakeCode() (string, error) {
 	var (
 		err  error
-		pc   uint   = 0
-		code string = ""
+		code strings.Builder
 		ins  *INS
 	)
 
-	for ; pc < uint(len(m.ROM)); pc += uint(ins.Bytes) {
-		ins, err = FindINS(m.ROM[pc])
+	b := m.banking
+	for off := uint(0); off < uint(len(m.ROM)); off += uint(ins.Bytes) {
+		bank, pc := m.BankAddr(off)
+		if b != nil {
+			// decode in this bank
+			m.banking = &Banking{Window: b.Window, Bank: func(*Machine) uint { return bank }}
+		}
+		ins, err = FindINS(m.ReadCODE(pc))
 		if err != nil {
-			return code, err
+			return code.String(), err
 		}
 		bbb := ""
 		for i := uint(0); i < uint(ins.Bytes); i++ {
-			bbb += fmt.Sprintf("%02X", m.ROM[pc+i])
-		}
-		code += fmt.Sprintf("%04X\t%s\t%s", pc, bbb, ins.Mnemonic)
-		if ins.FakeCode != nil {
-			code += fmt.Sprintf("\t%s", ins.FakeCode(m, pc))
+			bbb += fmt.Sprintf("%02X", m.ReadCODE(pc+i))
 		}
-		code += fmt.Sprintf("\n")
-	}
-	return code, nil
-}
-
-// Start 8051 machine
-func (m *Machine) Start() {
-	m.mainTick = time.NewTicker(m.Frequency)
-	m.exitCh = make(chan int, 1)
-	m.isrCh = make(chan int, 1)
-	defer m.mainTick.Stop()
-	defer close(m.exitCh)
-	defer close(m.isrCh)
-	for {
-		select {
-		case <-m.isrCh:
-		case <-m.mainTick.C:
-			m.Single()
-		case <-m.exitCh:
-			return
+		fmt.Fprintf(&code, "%s\t%s\t%s", m.CodeString(off), bbb, ins.Mnemonic)
+		if fake := ins.FakeCode(&m, pc); fake != "" {
+			fmt.Fprintf(&code, "\t%s", fake)
 		}
+		code.WriteString("\n")
 	}
+	return code.String(), nil
 }
 
-// Stop 8051 machine
-func (m *Machine) Stop() {
-	m.exitCh <- 1
+// Single 8051 machine, execute one instruction, error is *ExecError,
+// ErrBreak if not executed by breakpoint, the next Single execute it,
+// *WatchError if executed and hit watchpoint with Halt
+func (m *Machine) Single() error {
+	pc := m.PC
+	err := m.step()
+	hit := m.takeWatchHit()
+	switch {
+	case err != nil:
+	case m.breakReq && m.resume:
+		err = &ExecError{PC: pc, Opcode: m.ReadCODE(pc), Err: ErrBreak}
+	case hit != nil:
+		err = &ExecError{PC: hit.PC, Opcode: m.ReadCODE(hit.PC), Err: &WatchError{Hit: *hit}}
+	}
+	m.breakReq = false
+	return err
 }
 
-// Single 8051 machine
-func (m *Machine) Single() {
-	i, err := FindINS(m.ROM[m.PC])
+// step execute one instruction, or one sleep step in idle and power-down mode,
+// not executed if breakpoint call Break, move PC or reset, error is *ExecError
+func (m *Machine) step() error {
+	if m.SFR[PCON]&(pconIDL|pconPD) != 0 {
+		m.sleep()
+		// no instruction fetched in idle and power-down
+		return m.takeFault(ExecError{PC: m.PC, NoOpcode: true})
+	}
+	pc := m.PC
+	code := m.CodeAddr(m.Bank(), pc)
+	if code >= uint(len(m.ROM)) {
+		return &ExecError{PC: pc, NoOpcode: true, Err: ErrPCOutsideROM}
+	}
+	op := m.ROM[code]
+	i, err := FindINS(op)
 	if err != nil {
-		fmt.Printf("FindINS: PC:%X %s\n", m.PC, err)
-		m.Stop()
-		return
+		return &ExecError{PC: pc, Opcode: op, Err: ErrIllegalOpcode}
+	}
+	if code+uint(i.Bytes) > uint(len(m.ROM)) {
+		return &ExecError{PC: pc, Opcode: op, Err: ErrFetchPastROM}
 	}
-	fns, ok := m.brakepoints[m.PC]
-	if ok && (len(fns) != 0) {
-		for i := range fns {
-			fns[i](m)
+	if !m.resume {
+		resets := m.resets
+		m.hitBreakpoints(code)
+		if m.breakReq {
+			m.resume = true
+			return m.takeFault(ExecError{PC: pc, Opcode: op})
 		}
+		if m.PC != pc || m.resets != resets || m.CodeAddr(m.Bank(), pc) != code {
+			// breakpoint moved PC, reset or switched bank, execute the new PC in next step
+			return m.takeFault(ExecError{PC: pc, Opcode: op})
+		}
+	}
+	m.resume = false
+	if pc >= uint(m.Variant.ROMSize) {
+		// fetch from external CODE, bank window of banked code included
+		m.busAccess()
 	}
 	if i.Func != nil {
+		if m.recorder != nil {
+			m.recorder.begin(m, pc, code, i)
+		}
+		m.insPC, m.executing = pc, true
 		i.Func(m)
+		m.executing = false
+		if m.recorder != nil {
+			m.recorder.end(m)
+		}
+	}
+	m.tick(uint64(i.Cycles))
+	if c := m.pollInterrupt(); c != 0 {
+		m.tick(c)
 	}
+	return m.takeFault(ExecError{PC: pc, Opcode: op})
 }
 
-// ReadDATA read mechine DATA range
+// takeFault fault raised in this step as *ExecError of instruction e
+func (m *Machine) takeFault(e ExecError) error {
+	if m.fault == nil {
+		return nil
+	}
+	e.Err = m.fault
+	m.fault = nil
+	return &e
+}
+
+// tick run peripherals by machine cycles
+func (m *Machine) tick(cycles uint64) {
+	m.Cycles += cycles
+	for _, p := range m.peripherals {
+		p.tick(m, cycles)
+	}
+}
+
+// Time virtual time of machine, by machine cycles and crystal frequency
+func (m *Machine) Time() time.Duration {
+	return m.CyclesToTime(m.Cycles)
+}
+
+// CyclesToTime convert machine cycles to virtual time
+func (m *Machine) CyclesToTime(cycles uint64) time.Duration {
+	if m.Crystal == 0 {
+		return 0
+	}
+	clocks := cycles * uint64(m.ClocksPerCycle)
+	sec := clocks / uint64(m.Crystal)
+	rem := clocks % uint64(m.Crystal)
+	return time.Duration(sec)*time.Second + time.Duration(rem*uint64(time.Second)/uint64(m.Crystal))
+}
+
+// TimeToCycles convert virtual time to machine cycles, rounded down
+func (m *Machine) TimeToCycles(d time.Duration) uint64 {
+	if m.ClocksPerCycle == 0 || d <= 0 {
+		return 0
+	}
+	sec := uint64(d / time.Second)
+	rem := uint64(d % time.Second)
+	clocks := sec*uint64(m.Crystal) + rem*uint64(m.Crystal)/uint64(time.Second)
+	return clocks / uint64(m.ClocksPerCycle)
+}
+
+// direct direct addressing, 0x00~0x7F: DATA, 0x80~0xFF: SFR
+func (m *Machine) direct(addr uint8) *uint8 {
+	if addr < 0x80 {
+		return &m.DATA[addr]
+	}
+	return &m.SFR[addr]
+}
+
+// directSpace memory space of direct addressing
+func directSpace(addr uint8) Space {
+	if addr < 0x80 {
+		return SpaceDATA
+	}
+	return SpaceSFR
+}
+
+// ReadDATA read mechine DATA range by direct addressing
 func (m *Machine) ReadDATA(addr uint8) uint8 {
-	val := m.DATA[addr]
+	if addr == PSW {
+		m.updateParity()
+	}
+	val := *m.direct(addr)
+	if fn, ok := m.sfrRead[addr]; ok {
+		val = fn(m)
+	}
 	if hooks, ok := m.insHookDATAR[addr]; ok {
 		for _, hook := range hooks {
 			hook(m, val)
 		}
 	}
+	m.watch(directSpace(addr), uint(addr), WatchRead, val, val)
 	return val
 }
 
-// WriteDATA write mechine DATA range
+// WriteDATA write mechine DATA range by direct addressing
 func (m *Machine) WriteDATA(addr uint8, val uint8) {
+	p := m.direct(addr)
 	if hooks, ok := m.insHookDATAW[addr]; ok {
 		for _, hook := range hooks {
-			hook(m, m.DATA[addr], val)
+			hook(m, *p, val)
 		}
 	}
-	m.DATA[addr] = val
+	m.watch(directSpace(addr), uint(addr), WatchWrite, *p, val)
+	if fn, ok := m.sfrWrite[addr]; ok {
+		fn(m, val)
+		return
+	}
+	*p = val
+	if addr == ACC || addr == PSW {
+		// P flag is read only, always follow ACC
+		m.updateParity()
+	}
+}
+
+// ReadCODE read mechine CODE range in selected bank, out of ROM read as 0xFF
+func (m *Machine) ReadCODE(addr uint) uint8 {
+	addr = m.CodeAddr(m.Bank(), addr)
+	if addr >= uint(len(m.ROM)) {
+		return 0xFF
+	}
+	return m.ROM[addr]
+}
+
+// readCodeData read CODE range as data by MOVC, watched by ROM offset
+func (m *Machine) readCodeData(addr uint) uint8 {
+	val := m.ReadCODE(addr)
+	m.watch(SpaceCODE, m.CodeAddr(m.Bank(), addr), WatchRead, val, val)
+	return val
+}
+
+// ReadDPTR read data pointer DPH:DPL
+func (m *Machine) ReadDPTR() uint16 {
+	return uint16(m.ReadDATA(DPH))<<8 | uint16(m.ReadDATA(DPL))
+}
+
+// WriteDPTR write data pointer DPH:DPL
+func (m *Machine) WriteDPTR(val uint16) {
+	m.WriteDATA(DPH, uint8(val>>8))
+	m.WriteDATA(DPL, uint8(val))
+}
+
+// ReadIDATA read mechine DATA range by indirect addressing (@R0, @R1, SP)
+func (m *Machine) ReadIDATA(addr uint8) uint8 {
+	if addr < 0x80 {
+		return m.ReadDATA(addr)
+	}
+	val := uint8(0xFF)
+	if m.hasIDATA(addr) {
+		val = m.DATA[addr]
+	}
+	m.watch(SpaceIDATA, uint(addr), WatchRead, val, val)
+	return val
+}
+
+// WriteIDATA write mechine DATA range by indirect addressing (@R0, @R1, SP)
+func (m *Machine) WriteIDATA(addr uint8, val uint8) {
+	if addr < 0x80 {
+		m.WriteDATA(addr, val)
+		return
+	}
+	if m.hasIDATA(addr) {
+		m.watch(SpaceIDATA, uint(addr), WatchWrite, m.DATA[addr], val)
+		m.DATA[addr] = val
+	}
 }
 
-// Trace breakepoint
-func (m *Machine) Trace(addr uint, fn func(m *Machine)) {
-	if m.brakepoints[addr] == nil {
-		m.brakepoints[addr] = make([]func(m *Machine), 0)
+// hasIDATA internal RAM exists at address, upper 128 bytes on 8052 and later only
+func (m *Machine) hasIDATA(addr uint8) bool {
+	return m.Variant.IRAMSize == 0 || int(addr) < m.Variant.IRAMSize
+}
+
+// push SP = SP + 1, (SP) = val
+func (m *Machine) push(val uint8) {
+	sp := m.ReadDATA(SP) + 1
+	if sp == 0 || !m.hasIDATA(sp) {
+		m.Fault(ErrStackOverflow)
 	}
-	m.brakepoints[addr] = append(m.brakepoints[addr], fn)
+	m.WriteDATA(SP, sp)
+	m.WriteIDATA(sp, val)
+}
+
+// pop val = (SP), SP = SP - 1
+func (m *Machine) pop() uint8 {
+	sp := m.ReadDATA(SP)
+	val := m.ReadIDATA(sp)
+	m.WriteDATA(SP, sp-1)
+	return val
 }
 
 func (m *Machine) insideHookDATARead(addr uint8, fn func(m *Machine, val uint8)) {
@@ -187,10 +450,10 @@ func (m *Machine) GetBankSelect() int {
 		banksel = 0
 	)
 
-	if (m.DATA[PSW] & uint8(1<<3)) != 0 {
+	if (m.SFR[PSW] & uint8(1<<3)) != 0 {
 		banksel |= (1 << 0)
 	}
-	if (m.DATA[PSW] & uint8(1<<4)) != 0 {
+	if (m.SFR[PSW] & uint8(1<<4)) != 0 {
 		banksel |= (1 << 1)
 	}
 	return banksel
@@ -1,9 +1,11 @@
 package asm_test
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ma6254/go8051/asm"
 
@@ -60,14 +62,14 @@ func Test_Base(t *testing.T) {
 	})
 
 	m.Trace(0x0F, func(m *asm.Machine) {
-		t.Logf("%04X P0: %02X\n", m.PC, m.DATA[asm.P0])
-		if m.DATA[asm.P0] == 0xAA {
+		t.Logf("%04X P0: %02X\n", m.PC, m.SFR[asm.P0])
+		if m.SFR[asm.P0] == 0xAA {
 			OK_1 = true
 		}
 	})
 	m.Trace(0x12, func(m *asm.Machine) {
-		t.Logf("%04X P0: %02X\n", m.PC, m.DATA[asm.P0])
-		if m.DATA[asm.P0] == 0x55 {
+		t.Logf("%04X P0: %02X\n", m.PC, m.SFR[asm.P0])
+		if m.SFR[asm.P0] == 0x55 {
 			OK_2 = true
 		}
 	})
@@ -80,10 +82,15 @@ func Test_Base(t *testing.T) {
 	})
 
 	t.Logf("8051 Machine Running")
-	m.Start()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	m.Start(ctx)
+	if err := m.WaitState(ctx, asm.StateStopped); err != nil {
+		t.Fatal(err)
+	}
 
 	if m.DATA[asm.R0] == 0 {
-		if OK_0_cnt == (0x7F - 1) {
+		if OK_0_cnt == 0x7F {
 			OK_0 = true
 		} else {
 			OK_0 = false
@@ -170,33 +177,38 @@ func Test_Call_Func(t *testing.T) {
 	)
 
 	m.Trace(0x17, func(m *asm.Machine) {
-		sp0 = m.DATA[asm.SP]
-		t.Logf("%04X SP: %02X P1: %02X\n", m.PC, m.DATA[asm.SP], m.DATA[asm.P1])
+		sp0 = m.SFR[asm.SP]
+		t.Logf("%04X SP: %02X P1: %02X\n", m.PC, m.SFR[asm.SP], m.SFR[asm.P1])
 	})
 
 	m.Trace(0x1C, func(m *asm.Machine) {
-		sp2 = m.DATA[asm.SP]
-		t.Logf("%04X SP: %02X P1: %02X\n", m.PC, m.DATA[asm.SP], m.DATA[asm.P1])
+		sp2 = m.SFR[asm.SP]
+		t.Logf("%04X SP: %02X P1: %02X\n", m.PC, m.SFR[asm.SP], m.SFR[asm.P1])
 		m.Stop()
 	})
 
 	m.Trace(0x0D, func(m *asm.Machine) {
-		sp1 = m.DATA[asm.SP]
-		t.Logf("%04X SP: %02X P1: %02X\n", m.PC, m.DATA[asm.SP], m.DATA[asm.P1])
+		sp1 = m.SFR[asm.SP]
+		t.Logf("%04X SP: %02X P1: %02X\n", m.PC, m.SFR[asm.SP], m.SFR[asm.P1])
 	})
 
 	m.Trace(0x27, func(m *asm.Machine) {
-		t.Logf("%04X R0: %02X ACC:%02X\n", m.PC, m.DATA[asm.R0], m.DATA[asm.ACC])
+		t.Logf("%04X R0: %02X ACC:%02X\n", m.PC, m.DATA[asm.R0], m.SFR[asm.ACC])
 	})
 	m.Trace(0x27, func(m *asm.Machine) {
-		t.Logf("%04X R0: %02X ACC:%02X\n", m.PC, m.DATA[asm.R0], m.DATA[asm.ACC])
+		t.Logf("%04X R0: %02X ACC:%02X\n", m.PC, m.DATA[asm.R0], m.SFR[asm.ACC])
 	})
 	m.Trace(0x29, func(m *asm.Machine) {
 		t.Logf("%04X R0: %02X\n", m.PC, m.DATA[asm.R0])
 	})
 
 	t.Logf("8051 Machine Running")
-	m.Start()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	m.Start(ctx)
+	if err := m.WaitState(ctx, asm.StateStopped); err != nil {
+		t.Fatal(err)
+	}
 
 	if sp0 != sp2 {
 		t.Logf("sp restore fail %02X %02X", sp0, sp2)
@@ -206,3 +218,64 @@ func Test_Call_Func(t *testing.T) {
 	}
 
 }
+
+func Test_IDATA_SFR(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x75, 0x81, 0x8F, // 0000: MOV SP, #0x8F
+		0x78, 0x90, // 0003: MOV R0, #0x90
+		0x76, 0x5A, // 0005: MOV @R0, #0x5A
+		0x75, 0x90, 0xA5, // 0007: MOV P1, #0xA5
+		0xC0, 0x90, // 000A: PUSH P1
+		0x12, 0x00, 0x11, // 000C: LCALL 0011
+		0x80, 0xFE, // 000F: SJMP 000F
+		0xD0, 0xA0, // 0011: POP P2, return address high byte
+		0x22, // 0013: RET
+	}
+	for i := 0; i < 7; i++ {
+		m.Single()
+	}
+
+	if m.DATA[0x90] != 0xA5 {
+		t.Errorf("IDATA[90] %02X, PUSH should overwrite @R0 write", m.DATA[0x90])
+	}
+	if m.SFR[asm.P1] != 0xA5 {
+		t.Errorf("P1 %02X", m.SFR[asm.P1])
+	}
+	if m.DATA[0x91] != 0x0F || m.SFR[asm.P2] != 0x00 {
+		t.Errorf("stack: IDATA[91] %02X P2 %02X", m.DATA[0x91], m.SFR[asm.P2])
+	}
+	if m.SFR[asm.SP] != 0x91 {
+		t.Errorf("SP %02X", m.SFR[asm.SP])
+	}
+}
+
+func Test_Cycles(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x7F, 0x0A, // 0000: MOV R7, #0x0A
+		0xDF, 0xFE, // 0002: DJNZ R7, 0002
+		0xA4, // 0004: MUL AB
+		0x00, // 0005: NOP
+	}
+
+	var cycles uint64
+	m.Trace(0x05, func(m *asm.Machine) {
+		cycles = m.Cycles
+	})
+	for m.PC < uint(len(m.ROM)) {
+		m.Single()
+	}
+
+	// 1 + 10*2 + 4
+	if cycles != 25 {
+		t.Errorf("cycles at 0005: %d", cycles)
+	}
+	if m.Time() != 26*time.Microsecond {
+		t.Errorf("12MHz time %s", m.Time())
+	}
+	m.Crystal = asm.Crystal11_0592MHz
+	if d := m.CyclesToTime(921600); d != time.Second {
+		t.Errorf("11.0592MHz 921600 cycles %s", d)
+	}
+}
@@ -6,9 +6,112 @@ import "fmt"
 type INS struct {
 	Code     byte
 	Bytes    byte
+	Cycles   byte // machine cycles
 	Mnemonic string
 	Func     func(*Machine)
-	FakeCode func(Machine, uint) string
+	FakeCode func(*Machine, uint) string
+}
+
+// operand : where a instruction read or write one byte
+type operand struct {
+	pos   uint // operand byte position in instruction, 0: inside opcode
+	read  func(m *Machine) uint8
+	write func(m *Machine, val uint8)
+	fake  func(m *Machine, pc uint) string
+}
+
+// opA, "A"
+var opA = operand{
+	read:  func(m *Machine) uint8 { return m.ReadDATA(ACC) },
+	write: func(m *Machine, val uint8) { m.WriteDATA(ACC, val) },
+	fake:  func(m *Machine, pc uint) string { return "A" },
+}
+
+// opB, "B", only used by MUL/DIV
+var opB = operand{
+	read:  func(m *Machine) uint8 { return m.ReadDATA(B) },
+	write: func(m *Machine, val uint8) { m.WriteDATA(B, val) },
+	fake:  func(m *Machine, pc uint) string { return "B" },
+}
+
+// opRx, "Rx"
+func opRx(x uint8) operand {
+	return operand{
+		read:  func(m *Machine) uint8 { return m.ReadRx(x) },
+		write: func(m *Machine, val uint8) { m.WriteRx(x, val) },
+		fake:  func(m *Machine, pc uint) string { return fmt.Sprintf("R%d", x) },
+	}
+}
+
+// opIndirect, "@Rx", x: 0~1
+func opIndirect(x uint8) operand {
+	return operand{
+		read:  func(m *Machine) uint8 { return m.ReadIDATA(m.ReadRx(x)) },
+		write: func(m *Machine, val uint8) { m.WriteIDATA(m.ReadRx(x), val) },
+		fake:  func(m *Machine, pc uint) string { return fmt.Sprintf("@R%d", x) },
+	}
+}
+
+// opDirect, "direct" address at instruction byte pos
+func opDirect(pos uint) operand {
+	return operand{
+		pos:   pos,
+		read:  func(m *Machine) uint8 { return m.ReadDATA(m.ReadCODE(m.PC + pos)) },
+		write: func(m *Machine, val uint8) { m.WriteDATA(m.ReadCODE(m.PC+pos), val) },
+		fake:  func(m *Machine, pc uint) string { return fakeDirect(m, m.ReadCODE(pc+pos)) },
+	}
+}
+
+// opImmed, "#immed" at instruction byte pos
+func opImmed(pos uint) operand {
+	return operand{
+		pos:  pos,
+		read: func(m *Machine) uint8 { return m.ReadCODE(m.PC + pos) },
+		fake: func(m *Machine, pc uint) string { return fmt.Sprintf("#0x%02X", m.ReadCODE(pc+pos)) },
+	}
+}
+
+// insLen instruction length by operands
+func insLen(ops ...operand) uint {
+	l := uint(1)
+	for _, op := range ops {
+		if op.pos+1 > l {
+			l = op.pos + 1
+		}
+	}
+	return l
+}
+
+// fakeDirect direct address with register name
+func fakeDirect(m *Machine, addr uint8) string {
+	if addr >= 0x80 {
+		if r := FindRegByAddr(addr, m.regDefines); r != nil {
+			return fmt.Sprintf("%s(0x%02X)", r.Name, r.Addr)
+		}
+	}
+	return fmt.Sprintf("0x%02X", addr)
+}
+
+// fakeBit bit address with bit name, like "CY(0xD7)", "P1.3(0x93)", "0x20.1(0x01)"
+func fakeBit(m *Machine, bit uint8) string {
+	if r := FindRegByAddr(bit, bitList); r != nil {
+		return fmt.Sprintf("%s(0x%02X)", r.Name, bit)
+	}
+	addr, _ := BitAddr(bit)
+	if r := FindRegByAddr(addr, m.regDefines); r != nil && addr >= 0x80 {
+		return fmt.Sprintf("%s.%d(0x%02X)", r.Name, bit&0x07, bit)
+	}
+	return fmt.Sprintf("0x%02X.%d(0x%02X)", addr, bit&0x07, bit)
+}
+
+// relAddr relative jump destination, next: address of next instruction
+func relAddr(next uint, rel uint8) uint {
+	return uint(uint16(int(next) + int(int8(rel))))
+}
+
+func fakeRel(m *Machine, pc uint, pos uint) string {
+	offset := int8(m.ReadCODE(pc + pos))
+	return fmt.Sprintf("C:%d(%04X)", offset, relAddr(pc+pos+1, uint8(offset)))
 }
 
 func genNOPn(x int) func(m *Machine) {
@@ -17,93 +120,343 @@ func genNOPn(x int) func(m *Machine) {
 	}
 }
 
-// genMOVRxImmed, "MOV Rx #immed" ,ins code 78~7F
-func genMOVRxImmed(x uint8) func(m *Machine) {
+// genFakeCode, "dst src ..."
+func genFakeCode(ops ...operand) func(m *Machine, pc uint) string {
+	return func(m *Machine, pc uint) string {
+		s := ""
+		for k, op := range ops {
+			if k != 0 {
+				s += " "
+			}
+			s += op.fake(m, pc)
+		}
+		return s
+	}
+}
+
+// genMOV, "MOV dst, src"
+func genMOV(dst, src operand) func(m *Machine) {
 	return func(m *Machine) {
-		m.WriteRx(x, m.ROM[m.PC+1])
-		m.PC += 2
+		dst.write(m, src.read(m))
+		m.PC += insLen(dst, src)
 	}
 }
 
-func genMOVRxImmedFakeCode(x uint8) func(m Machine, pc uint) string {
-	return func(m Machine, pc uint) string {
-		return fmt.Sprintf("R%d #0x%02X", x, m.ROM[pc+1])
+// readRMW read destination of read-modify-write instruction, port read latch instead of pins
+func readRMW(m *Machine, dst operand) uint8 {
+	m.rmw = true
+	val := dst.read(m)
+	m.rmw = false
+	return val
+}
+
+// genALU, "ADD/ADDC/SUBB/ANL/ORL/XRL dst, src"
+func genALU(fn func(m *Machine, a, b uint8) uint8, dst, src operand) func(m *Machine) {
+	return func(m *Machine) {
+		a := readRMW(m, dst)
+		dst.write(m, fn(m, a, src.read(m)))
+		m.PC += insLen(dst, src)
 	}
 }
 
-// genMOVdirectRx, "MOV direct Rx" ,ins code 88~8F
-func genMOVdirectRx(x uint8) func(m *Machine) {
+// genUnary, "INC/DEC/RL/RR/... dst"
+func genUnary(fn func(m *Machine, a uint8) uint8, dst operand) func(m *Machine) {
 	return func(m *Machine) {
-		m.WriteDATA(m.ROM[m.PC+1], m.ReadRx(x))
-		m.PC += 2
+		dst.write(m, fn(m, readRMW(m, dst)))
+		m.PC += insLen(dst)
 	}
 }
 
-// genMOVARx, "MOV A, Rx", ins code E8~EF
-func genMOVARx(x uint8) func(m *Machine) {
+// genXCH, "XCH A, src"
+func genXCH(src operand) func(m *Machine) {
 	return func(m *Machine) {
-		m.WriteDATA(ACC, m.ReadRx(x))
-		m.PC++
+		a := opA.read(m)
+		opA.write(m, src.read(m))
+		src.write(m, a)
+		m.PC += insLen(src)
 	}
 }
 
-// genMOVRxA, "MOV Rx, A", ins code F8~FF
-func genMOVRxA(x uint8) func(m *Machine) {
+// genXCHD, "XCHD A, @Rx"
+func genXCHD(x uint8) func(m *Machine) {
 	return func(m *Machine) {
-		m.WriteRx(x, m.ReadDATA(ACC))
+		src := opIndirect(x)
+		a := opA.read(m)
+		b := src.read(m)
+		opA.write(m, (a&0xF0)|(b&0x0F))
+		src.write(m, (b&0xF0)|(a&0x0F))
 		m.PC++
 	}
 }
 
-// genMOVRxAFakeCode, "MOV Rx, A", ins code F8~FF
-func genMOVRxAFakeCode(x uint8) func(m Machine, pc uint) string {
-	return func(m Machine, pc uint) string {
-		return fmt.Sprintf("R%d A", x)
+// genDJNZ, "DJNZ dst, offset"
+func genDJNZ(dst operand) func(m *Machine) {
+	return func(m *Machine) {
+		l := insLen(dst) + 1
+		rel := m.ReadCODE(m.PC + l - 1)
+		val := readRMW(m, dst) - 1
+		dst.write(m, val)
+		if val != 0 {
+			m.PC = relAddr(m.PC+l, rel)
+		} else {
+			m.PC += l
+		}
 	}
 }
 
-// genDJNZRxOffset, "DJNZ Rx, offset", ins code D8~DF
-func genDJNZRxOffset(x uint8) func(m *Machine) {
+func genDJNZFakeCode(dst operand) func(m *Machine, pc uint) string {
+	return func(m *Machine, pc uint) string {
+		return fmt.Sprintf("%s %s", dst.fake(m, pc), fakeRel(m, pc, insLen(dst)))
+	}
+}
+
+// genCJNE, "CJNE a, b, offset"
+func genCJNE(a, b operand) func(m *Machine) {
 	return func(m *Machine) {
-		offset := int8(m.ROM[m.PC+1])
-		m.WriteRx(x, m.ReadRx(x)-1)
-		if (m.ReadRx(x)) != 0 {
-			if offset > 0 {
-				m.PC += uint(offset)
-			} else {
-				m.PC -= uint(-offset)
-			}
+		x := a.read(m)
+		y := b.read(m)
+		m.setCarry(x < y)
+		if x != y {
+			m.PC = relAddr(m.PC+3, m.ReadCODE(m.PC+2))
+		} else {
+			m.PC += 3
+		}
+	}
+}
+
+func genCJNEFakeCode(a, b operand) func(m *Machine, pc uint) string {
+	return func(m *Machine, pc uint) string {
+		return fmt.Sprintf("%s %s %s", a.fake(m, pc), b.fake(m, pc), fakeRel(m, pc, 2))
+	}
+}
+
+// genJcond, "JC/JNC/JZ/JNZ offset"
+func genJcond(cond func(m *Machine) bool) func(m *Machine) {
+	return func(m *Machine) {
+		if cond(m) {
+			m.PC = relAddr(m.PC+2, m.ReadCODE(m.PC+1))
 		} else {
 			m.PC += 2
 		}
 	}
 }
 
-func genDJNZRxOffsetFakeCode(x uint8) func(m Machine, pc uint) string {
-	return func(m Machine, pc uint) string {
-		offset := int8(m.ROM[pc+1])
-		pc += 2
-		if offset > 0 {
-			pc += uint(offset)
+func fakeJcond(m *Machine, pc uint) string {
+	return fakeRel(m, pc, 1)
+}
+
+// genJbit, "JB/JNB/JBC bit, offset"
+func genJbit(val bool, clear bool) func(m *Machine) {
+	return func(m *Machine) {
+		bit := m.ReadCODE(m.PC + 1)
+		// JBC is read-modify-write
+		m.rmw = clear
+		b := m.ReadBit(bit)
+		m.rmw = false
+		if b == val {
+			if clear {
+				m.WriteBit(bit, false)
+			}
+			m.PC = relAddr(m.PC+3, m.ReadCODE(m.PC+2))
 		} else {
-			pc -= uint(-offset)
+			m.PC += 3
+		}
+	}
+}
+
+func fakeJbit(m *Machine, pc uint) string {
+	return fmt.Sprintf("%s %s", fakeBit(m, m.ReadCODE(pc+1)), fakeRel(m, pc, 2))
+}
+
+// genAJMP, "AJMP addr11", page: opcode bit7~5
+func genAJMP(page uint8) func(m *Machine) {
+	return func(m *Machine) {
+		m.PC = addr11(m.PC+2, page, m.ReadCODE(m.PC+1))
+	}
+}
+
+// genACALL, "ACALL addr11", page: opcode bit7~5
+func genACALL(page uint8) func(m *Machine) {
+	return func(m *Machine) {
+		addr := addr11(m.PC+2, page, m.ReadCODE(m.PC+1))
+		m.PC += 2
+		m.push(uint8(m.PC))
+		m.push(uint8(m.PC >> 8))
+		m.PC = addr
+	}
+}
+
+func genAddr11FakeCode(page uint8) func(m *Machine, pc uint) string {
+	return func(m *Machine, pc uint) string {
+		return fmt.Sprintf("C:%04X", addr11(pc+2, page, m.ReadCODE(pc+1)))
+	}
+}
+
+// addr11 PC15~11 + page(a10~a8) + a7~a0
+func addr11(next uint, page uint8, low uint8) uint {
+	return (next & 0xF800) | uint(page)<<8 | uint(low)
+}
 
+// genBitOp, "SETB/CLR/CPL bit"
+func genBitOp(fn func(m *Machine, v bool) bool) func(m *Machine) {
+	return func(m *Machine) {
+		bit := m.ReadCODE(m.PC + 1)
+		m.rmw = true
+		b := m.ReadBit(bit)
+		m.rmw = false
+		m.WriteBit(bit, fn(m, b))
+		m.PC += 2
+	}
+}
+
+// genCarryBit, "ANL/ORL/MOV C, bit" or "C, /bit"
+func genCarryBit(fn func(c, b bool) bool, not bool) func(m *Machine) {
+	return func(m *Machine) {
+		b := m.ReadBit(m.ReadCODE(m.PC + 1))
+		if not {
+			b = !b
+		}
+		m.setCarry(fn(m.carry(), b))
+		m.PC += 2
+	}
+}
+
+func genCarryBitFakeCode(not bool) func(m *Machine, pc uint) string {
+	return func(m *Machine, pc uint) string {
+		if not {
+			return fmt.Sprintf("C /%s", fakeBit(m, m.ReadCODE(pc+1)))
 		}
-		return fmt.Sprintf("C:%d(%04X)", offset, pc)
+		return fmt.Sprintf("C %s", fakeBit(m, m.ReadCODE(pc+1)))
+	}
+}
+
+// genCarryOp, "SETB/CLR/CPL C"
+func genCarryOp(fn func(c bool) bool) func(m *Machine) {
+	return func(m *Machine) {
+		m.setCarry(fn(m.carry()))
+		m.PC++
+	}
+}
+
+// genMOVXRead, "MOVX A, @Rx", P2 latch as high byte of address
+func genMOVXRead(x uint8) func(m *Machine) {
+	return func(m *Machine) {
+		addr := uint16(m.SFR[P2])<<8 | uint16(m.ReadRx(x))
+		m.WriteDATA(ACC, m.ReadXDATA(addr))
+		m.PC++
+	}
+}
+
+// genMOVXWrite, "MOVX @Rx, A", P2 latch as high byte of address
+func genMOVXWrite(x uint8) func(m *Machine) {
+	return func(m *Machine) {
+		addr := uint16(m.SFR[P2])<<8 | uint16(m.ReadRx(x))
+		m.WriteXDATA(addr, m.ReadDATA(ACC))
+		m.PC++
+	}
+}
+
+func fakeString(s string) func(m *Machine, pc uint) string {
+	return func(m *Machine, pc uint) string { return s }
+}
+
+func fakeDirectA(m *Machine, pc uint) string {
+	return genFakeCode(opDirect(1), opA)(m, pc)
+}
+
+func fakeBitOp(m *Machine, pc uint) string {
+	return fakeBit(m, m.ReadCODE(pc+1))
+}
+
+func aluADD(m *Machine, a, b uint8) uint8 {
+	return m.add(a, b, false)
+}
+
+func aluADDC(m *Machine, a, b uint8) uint8 {
+	return m.add(a, b, m.carry())
+}
+
+func aluSUBB(m *Machine, a, b uint8) uint8 {
+	return m.subb(a, b, m.carry())
+}
+
+func aluANL(m *Machine, a, b uint8) uint8 { return a & b }
+func aluORL(m *Machine, a, b uint8) uint8 { return a | b }
+func aluXRL(m *Machine, a, b uint8) uint8 { return a ^ b }
+
+func aluINC(m *Machine, a uint8) uint8  { return a + 1 }
+func aluDEC(m *Machine, a uint8) uint8  { return a - 1 }
+func aluCPL(m *Machine, a uint8) uint8  { return ^a }
+func aluCLR(m *Machine, a uint8) uint8  { return 0 }
+func aluRL(m *Machine, a uint8) uint8   { return a<<1 | a>>7 }
+func aluRR(m *Machine, a uint8) uint8   { return a>>1 | a<<7 }
+func aluSWAP(m *Machine, a uint8) uint8 { return a<<4 | a>>4 }
+
+func aluRLC(m *Machine, a uint8) uint8 {
+	c := m.carry()
+	m.setCarry(a&0x80 != 0)
+	a <<= 1
+	if c {
+		a |= 0x01
 	}
+	return a
+}
+
+func aluRRC(m *Machine, a uint8) uint8 {
+	c := m.carry()
+	m.setCarry(a&0x01 != 0)
+	a >>= 1
+	if c {
+		a |= 0x80
+	}
+	return a
+}
+
+// aluDA, "DA A" decimal adjust after addition
+func aluDA(m *Machine, a uint8) uint8 {
+	v := uint(a)
+	if (v&0x0F) > 9 || m.auxCarry() {
+		v += 0x06
+	}
+	if v > 0xFF {
+		m.setCarry(true)
+	}
+	if ((v>>4)&0x0F) > 9 || m.carry() {
+		v += 0x60
+	}
+	if v > 0xFF {
+		m.setCarry(true)
+	}
+	return uint8(v)
 }
 
 // Instructions : The following table lists the 8051 instructions by HEX code.
 var Instructions = [0xFF]INS{
-	{Code: 0x00, Bytes: 1, Mnemonic: "NOP", Func: genNOPn(1)},
-	{Code: 0x02, Bytes: 3, Mnemonic: "LJMP", Func: func(m *Machine) {
-		addrH := uint(m.ROM[m.PC+1])
-		addrL := uint(m.ROM[m.PC+2])
+	{Code: 0x00, Bytes: 1, Cycles: 1, Mnemonic: "NOP", Func: genNOPn(1), FakeCode: fakeString("")},
+	{Code: 0x01, Bytes: 2, Cycles: 2, Mnemonic: "AJMP", Func: genAJMP(0), FakeCode: genAddr11FakeCode(0)},
+	{Code: 0x02, Bytes: 3, Cycles: 2, Mnemonic: "LJMP", Func: func(m *Machine) {
+		addrH := uint(m.ReadCODE(m.PC + 1))
+		addrL := uint(m.ReadCODE(m.PC + 2))
 		m.PC = (addrH << 8) | addrL
-	}, FakeCode: func(m Machine, pc uint) string {
-		return fmt.Sprintf("C:%04X", (uint(m.ROM[pc+1])<<8)|uint(m.ROM[pc+2]))
+	}, FakeCode: func(m *Machine, pc uint) string {
+		return fmt.Sprintf("C:%04X", (uint(m.ReadCODE(pc+1))<<8)|uint(m.ReadCODE(pc+2)))
 	}},
-	{Code: 0x12, Bytes: 3, Mnemonic: "LCALL", Func: func(m *Machine) {
+	{Code: 0x03, Bytes: 1, Cycles: 1, Mnemonic: "RR", Func: genUnary(aluRR, opA), FakeCode: genFakeCode(opA)},
+	{Code: 0x04, Bytes: 1, Cycles: 1, Mnemonic: "INC", Func: genUnary(aluINC, opA), FakeCode: genFakeCode(opA)},
+	{Code: 0x05, Bytes: 2, Cycles: 1, Mnemonic: "INC", Func: genUnary(aluINC, opDirect(1)), FakeCode: genFakeCode(opDirect(1))},
+	{Code: 0x06, Bytes: 1, Cycles: 1, Mnemonic: "INC", Func: genUnary(aluINC, opIndirect(0)), FakeCode: genFakeCode(opIndirect(0))},
+	{Code: 0x07, Bytes: 1, Cycles: 1, Mnemonic: "INC", Func: genUnary(aluINC, opIndirect(1)), FakeCode: genFakeCode(opIndirect(1))},
+	{Code: 0x08, Bytes: 1, Cycles: 1, Mnemonic: "INC", Func: genUnary(aluINC, opRx(0)), FakeCode: genFakeCode(opRx(0))},
+	{Code: 0x09, Bytes: 1, Cycles: 1, Mnemonic: "INC", Func: genUnary(aluINC, opRx(1)), FakeCode: genFakeCode(opRx(1))},
+	{Code: 0x0A, Bytes: 1, Cycles: 1, Mnemonic: "INC", Func: genUnary(aluINC, opRx(2)), FakeCode: genFakeCode(opRx(2))},
+	{Code: 0x0B, Bytes: 1, Cycles: 1, Mnemonic: "INC", Func: genUnary(aluINC, opRx(3)), FakeCode: genFakeCode(opRx(3))},
+	{Code: 0x0C, Bytes: 1, Cycles: 1, Mnemonic: "INC", Func: genUnary(aluINC, opRx(4)), FakeCode: genFakeCode(opRx(4))},
+	{Code: 0x0D, Bytes: 1, Cycles: 1, Mnemonic: "INC", Func: genUnary(aluINC, opRx(5)), FakeCode: genFakeCode(opRx(5))},
+	{Code: 0x0E, Bytes: 1, Cycles: 1, Mnemonic: "INC", Func: genUnary(aluINC, opRx(6)), FakeCode: genFakeCode(opRx(6))},
+	{Code: 0x0F, Bytes: 1, Cycles: 1, Mnemonic: "INC", Func: genUnary(aluINC, opRx(7)), FakeCode: genFakeCode(opRx(7))},
+	{Code: 0x10, Bytes: 3, Cycles: 2, Mnemonic: "JBC", Func: genJbit(true, true), FakeCode: fakeJbit},
+	{Code: 0x11, Bytes: 2, Cycles: 2, Mnemonic: "ACALL", Func: genACALL(0), FakeCode: genAddr11FakeCode(0)},
+	{Code: 0x12, Bytes: 3, Cycles: 2, Mnemonic: "LCALL", Func: func(m *Machine) {
 		/*
 			PC = PC + 3
 			SP = SP + 1
@@ -112,165 +465,332 @@ var Instructions = [0xFF]INS{
 			(SP) = PC[15-8]
 			PC = addr16
 		*/
-		addrH := uint(m.ROM[m.PC+1])
-		addrL := uint(m.ROM[m.PC+2])
+		addrH := uint(m.ReadCODE(m.PC + 1))
+		addrL := uint(m.ReadCODE(m.PC + 2))
 		m.PC += 3
-
-		m.WriteDATA(SP, m.ReadDATA(SP)+1)
-		m.WriteDATA(m.ReadDATA(SP), uint8(m.PC))
-		m.WriteDATA(SP, m.ReadDATA(SP)+1)
-		m.WriteDATA(m.ReadDATA(SP), uint8(m.PC>>8))
+		m.push(uint8(m.PC))
+		m.push(uint8(m.PC >> 8))
 		m.PC = (addrH << 8) | addrL
-	}, FakeCode: func(m Machine, pc uint) string {
-		return fmt.Sprintf("C:0x%02X%02X", m.ROM[pc+1], m.ROM[pc+2])
+	}, FakeCode: func(m *Machine, pc uint) string {
+		return fmt.Sprintf("C:0x%02X%02X", m.ReadCODE(pc+1), m.ReadCODE(pc+2))
 	}},
-	{Code: 0x22, Bytes: 1, Mnemonic: "RET", Func: func(m *Machine) {
+	{Code: 0x13, Bytes: 1, Cycles: 1, Mnemonic: "RRC", Func: genUnary(aluRRC, opA), FakeCode: genFakeCode(opA)},
+	{Code: 0x14, Bytes: 1, Cycles: 1, Mnemonic: "DEC", Func: genUnary(aluDEC, opA), FakeCode: genFakeCode(opA)},
+	{Code: 0x15, Bytes: 2, Cycles: 1, Mnemonic: "DEC", Func: genUnary(aluDEC, opDirect(1)), FakeCode: genFakeCode(opDirect(1))},
+	{Code: 0x16, Bytes: 1, Cycles: 1, Mnemonic: "DEC", Func: genUnary(aluDEC, opIndirect(0)), FakeCode: genFakeCode(opIndirect(0))},
+	{Code: 0x17, Bytes: 1, Cycles: 1, Mnemonic: "DEC", Func: genUnary(aluDEC, opIndirect(1)), FakeCode: genFakeCode(opIndirect(1))},
+	{Code: 0x18, Bytes: 1, Cycles: 1, Mnemonic: "DEC", Func: genUnary(aluDEC, opRx(0)), FakeCode: genFakeCode(opRx(0))},
+	{Code: 0x19, Bytes: 1, Cycles: 1, Mnemonic: "DEC", Func: genUnary(aluDEC, opRx(1)), FakeCode: genFakeCode(opRx(1))},
+	{Code: 0x1A, Bytes: 1, Cycles: 1, Mnemonic: "DEC", Func: genUnary(aluDEC, opRx(2)), FakeCode: genFakeCode(opRx(2))},
+	{Code: 0x1B, Bytes: 1, Cycles: 1, Mnemonic: "DEC", Func: genUnary(aluDEC, opRx(3)), FakeCode: genFakeCode(opRx(3))},
+	{Code: 0x1C, Bytes: 1, Cycles: 1, Mnemonic: "DEC", Func: genUnary(aluDEC, opRx(4)), FakeCode: genFakeCode(opRx(4))},
+	{Code: 0x1D, Bytes: 1, Cycles: 1, Mnemonic: "DEC", Func: genUnary(aluDEC, opRx(5)), FakeCode: genFakeCode(opRx(5))},
+	{Code: 0x1E, Bytes: 1, Cycles: 1, Mnemonic: "DEC", Func: genUnary(aluDEC, opRx(6)), FakeCode: genFakeCode(opRx(6))},
+	{Code: 0x1F, Bytes: 1, Cycles: 1, Mnemonic: "DEC", Func: genUnary(aluDEC, opRx(7)), FakeCode: genFakeCode(opRx(7))},
+	{Code: 0x20, Bytes: 3, Cycles: 2, Mnemonic: "JB", Func: genJbit(true, false), FakeCode: fakeJbit},
+	{Code: 0x21, Bytes: 2, Cycles: 2, Mnemonic: "AJMP", Func: genAJMP(1), FakeCode: genAddr11FakeCode(1)},
+	{Code: 0x22, Bytes: 1, Cycles: 2, Mnemonic: "RET", Func: func(m *Machine) {
 		/*
 			PC15-8 = (SP)
 			SP = SP - 1
 			PC7-0 = (SP)
 			SP = SP - 1
 		*/
-		addrH := m.ReadDATA(m.ReadDATA(SP))
-		m.WriteDATA(SP, m.ReadDATA(SP)-1)
-		addrL := m.ReadDATA(m.ReadDATA(SP))
-		m.WriteDATA(SP, m.ReadDATA(SP)-1)
+		addrH := m.pop()
+		addrL := m.pop()
 		m.PC = (uint(addrH) << 8) | uint(addrL)
-	}},
-	{Code: 0x75, Bytes: 0x03, Mnemonic: "MOV", Func: func(m *Machine) {
-		m.WriteDATA(m.ROM[m.PC+1], m.ROM[m.PC+2])
-		m.PC += 3
-	}, FakeCode: func(m Machine, pc uint) string {
-		s := ""
-		if r := FindRegByAddr(m.ROM[pc+1], m.regDefines); r != nil {
-			s += fmt.Sprintf("%s(0x%02X)", r.Name, r.Addr)
-		} else {
-			s += fmt.Sprintf("0x%02X", m.ROM[pc+1])
-		}
-		s += fmt.Sprintf(" #0x%02X", m.ROM[pc+2])
-		return s
-	}},
-	{Code: 0x78, Bytes: 2, Mnemonic: "MOV", Func: genMOVRxImmed(0), FakeCode: genMOVRxImmedFakeCode(0)},
-	{Code: 0x79, Bytes: 2, Mnemonic: "MOV", Func: genMOVRxImmed(1), FakeCode: genMOVRxImmedFakeCode(1)},
-	{Code: 0x7A, Bytes: 2, Mnemonic: "MOV", Func: genMOVRxImmed(2), FakeCode: genMOVRxImmedFakeCode(2)},
-	{Code: 0x7B, Bytes: 2, Mnemonic: "MOV", Func: genMOVRxImmed(3), FakeCode: genMOVRxImmedFakeCode(3)},
-	{Code: 0x7C, Bytes: 2, Mnemonic: "MOV", Func: genMOVRxImmed(4), FakeCode: genMOVRxImmedFakeCode(4)},
-	{Code: 0x7D, Bytes: 2, Mnemonic: "MOV", Func: genMOVRxImmed(5), FakeCode: genMOVRxImmedFakeCode(5)},
-	{Code: 0x7E, Bytes: 2, Mnemonic: "MOV", Func: genMOVRxImmed(6), FakeCode: genMOVRxImmedFakeCode(6)},
-	{Code: 0x7F, Bytes: 2, Mnemonic: "MOV", Func: genMOVRxImmed(7), FakeCode: genMOVRxImmedFakeCode(7)},
-	{Code: 0x80, Bytes: 2, Mnemonic: "SJMP", Func: func(m *Machine) {
-		offset := int8(m.ROM[m.PC+1])
-		m.PC += 2
-		if offset > 0 {
-			m.PC += uint(offset)
-		} else {
-			m.PC -= uint(-offset)
-		}
-	}, FakeCode: func(m Machine, pc uint) string {
-		offset := int8(m.ROM[pc+1])
-		pc += 2
-		if offset > 0 {
-			pc += uint(offset)
-		} else {
-			pc -= uint(-offset)
+	}, FakeCode: fakeString("")},
+	{Code: 0x23, Bytes: 1, Cycles: 1, Mnemonic: "RL", Func: genUnary(aluRL, opA), FakeCode: genFakeCode(opA)},
+	{Code: 0x24, Bytes: 2, Cycles: 1, Mnemonic: "ADD", Func: genALU(aluADD, opA, opImmed(1)), FakeCode: genFakeCode(opA, opImmed(1))},
+	{Code: 0x25, Bytes: 2, Cycles: 1, Mnemonic: "ADD", Func: genALU(aluADD, opA, opDirect(1)), FakeCode: genFakeCode(opA, opDirect(1))},
+	{Code: 0x26, Bytes: 1, Cycles: 1, Mnemonic: "ADD", Func: genALU(aluADD, opA, opIndirect(0)), FakeCode: genFakeCode(opA, opIndirect(0))},
+	{Code: 0x27, Bytes: 1, Cycles: 1, Mnemonic: "ADD", Func: genALU(aluADD, opA, opIndirect(1)), FakeCode: genFakeCode(opA, opIndirect(1))},
+	{Code: 0x28, Bytes: 1, Cycles: 1, Mnemonic: "ADD", Func: genALU(aluADD, opA, opRx(0)), FakeCode: genFakeCode(opA, opRx(0))},
+	{Code: 0x29, Bytes: 1, Cycles: 1, Mnemonic: "ADD", Func: genALU(aluADD, opA, opRx(1)), FakeCode: genFakeCode(opA, opRx(1))},
+	{Code: 0x2A, Bytes: 1, Cycles: 1, Mnemonic: "ADD", Func: genALU(aluADD, opA, opRx(2)), FakeCode: genFakeCode(opA, opRx(2))},
+	{Code: 0x2B, Bytes: 1, Cycles: 1, Mnemonic: "ADD", Func: genALU(aluADD, opA, opRx(3)), FakeCode: genFakeCode(opA, opRx(3))},
+	{Code: 0x2C, Bytes: 1, Cycles: 1, Mnemonic: "ADD", Func: genALU(aluADD, opA, opRx(4)), FakeCode: genFakeCode(opA, opRx(4))},
+	{Code: 0x2D, Bytes: 1, Cycles: 1, Mnemonic: "ADD", Func: genALU(aluADD, opA, opRx(5)), FakeCode: genFakeCode(opA, opRx(5))},
+	{Code: 0x2E, Bytes: 1, Cycles: 1, Mnemonic: "ADD", Func: genALU(aluADD, opA, opRx(6)), FakeCode: genFakeCode(opA, opRx(6))},
+	{Code: 0x2F, Bytes: 1, Cycles: 1, Mnemonic: "ADD", Func: genALU(aluADD, opA, opRx(7)), FakeCode: genFakeCode(opA, opRx(7))},
+	{Code: 0x30, Bytes: 3, Cycles: 2, Mnemonic: "JNB", Func: genJbit(false, false), FakeCode: fakeJbit},
+	{Code: 0x31, Bytes: 2, Cycles: 2, Mnemonic: "ACALL", Func: genACALL(1), FakeCode: genAddr11FakeCode(1)},
+	{Code: 0x32, Bytes: 1, Cycles: 2, Mnemonic: "RETI", Func: func(m *Machine) {
+		addrH := m.pop()
+		addrL := m.pop()
+		m.PC = (uint(addrH) << 8) | uint(addrL)
+		m.reti()
+	}, FakeCode: fakeString("")},
+	{Code: 0x33, Bytes: 1, Cycles: 1, Mnemonic: "RLC", Func: genUnary(aluRLC, opA), FakeCode: genFakeCode(opA)},
+	{Code: 0x34, Bytes: 2, Cycles: 1, Mnemonic: "ADDC", Func: genALU(aluADDC, opA, opImmed(1)), FakeCode: genFakeCode(opA, opImmed(1))},
+	{Code: 0x35, Bytes: 2, Cycles: 1, Mnemonic: "ADDC", Func: genALU(aluADDC, opA, opDirect(1)), FakeCode: genFakeCode(opA, opDirect(1))},
+	{Code: 0x36, Bytes: 1, Cycles: 1, Mnemonic: "ADDC", Func: genALU(aluADDC, opA, opIndirect(0)), FakeCode: genFakeCode(opA, opIndirect(0))},
+	{Code: 0x37, Bytes: 1, Cycles: 1, Mnemonic: "ADDC", Func: genALU(aluADDC, opA, opIndirect(1)), FakeCode: genFakeCode(opA, opIndirect(1))},
+	{Code: 0x38, Bytes: 1, Cycles: 1, Mnemonic: "ADDC", Func: genALU(aluADDC, opA, opRx(0)), FakeCode: genFakeCode(opA, opRx(0))},
+	{Code: 0x39, Bytes: 1, Cycles: 1, Mnemonic: "ADDC", Func: genALU(aluADDC, opA, opRx(1)), FakeCode: genFakeCode(opA, opRx(1))},
+	{Code: 0x3A, Bytes: 1, Cycles: 1, Mnemonic: "ADDC", Func: genALU(aluADDC, opA, opRx(2)), FakeCode: genFakeCode(opA, opRx(2))},
+	{Code: 0x3B, Bytes: 1, Cycles: 1, Mnemonic: "ADDC", Func: genALU(aluADDC, opA, opRx(3)), FakeCode: genFakeCode(opA, opRx(3))},
+	{Code: 0x3C, Bytes: 1, Cycles: 1, Mnemonic: "ADDC", Func: genALU(aluADDC, opA, opRx(4)), FakeCode: genFakeCode(opA, opRx(4))},
+	{Code: 0x3D, Bytes: 1, Cycles: 1, Mnemonic: "ADDC", Func: genALU(aluADDC, opA, opRx(5)), FakeCode: genFakeCode(opA, opRx(5))},
+	{Code: 0x3E, Bytes: 1, Cycles: 1, Mnemonic: "ADDC", Func: genALU(aluADDC, opA, opRx(6)), FakeCode: genFakeCode(opA, opRx(6))},
+	{Code: 0x3F, Bytes: 1, Cycles: 1, Mnemonic: "ADDC", Func: genALU(aluADDC, opA, opRx(7)), FakeCode: genFakeCode(opA, opRx(7))},
+	{Code: 0x40, Bytes: 2, Cycles: 2, Mnemonic: "JC", Func: genJcond(func(m *Machine) bool { return m.carry() }), FakeCode: fakeJcond},
+	{Code: 0x41, Bytes: 2, Cycles: 2, Mnemonic: "AJMP", Func: genAJMP(2), FakeCode: genAddr11FakeCode(2)},
+	{Code: 0x42, Bytes: 2, Cycles: 1, Mnemonic: "ORL", Func: genALU(aluORL, opDirect(1), opA), FakeCode: genFakeCode(opDirect(1), opA)},
+	{Code: 0x43, Bytes: 3, Cycles: 2, Mnemonic: "ORL", Func: genALU(aluORL, opDirect(1), opImmed(2)), FakeCode: genFakeCode(opDirect(1), opImmed(2))},
+	{Code: 0x44, Bytes: 2, Cycles: 1, Mnemonic: "ORL", Func: genALU(aluORL, opA, opImmed(1)), FakeCode: genFakeCode(opA, opImmed(1))},
+	{Code: 0x45, Bytes: 2, Cycles: 1, Mnemonic: "ORL", Func: genALU(aluORL, opA, opDirect(1)), FakeCode: genFakeCode(opA, opDirect(1))},
+	{Code: 0x46, Bytes: 1, Cycles: 1, Mnemonic: "ORL", Func: genALU(aluORL, opA, opIndirect(0)), FakeCode: genFakeCode(opA, opIndirect(0))},
+	{Code: 0x47, Bytes: 1, Cycles: 1, Mnemonic: "ORL", Func: genALU(aluORL, opA, opIndirect(1)), FakeCode: genFakeCode(opA, opIndirect(1))},
+	{Code: 0x48, Bytes: 1, Cycles: 1, Mnemonic: "ORL", Func: genALU(aluORL, opA, opRx(0)), FakeCode: genFakeCode(opA, opRx(0))},
+	{Code: 0x49, Bytes: 1, Cycles: 1, Mnemonic: "ORL", Func: genALU(aluORL, opA, opRx(1)), FakeCode: genFakeCode(opA, opRx(1))},
+	{Code: 0x4A, Bytes: 1, Cycles: 1, Mnemonic: "ORL", Func: genALU(aluORL, opA, opRx(2)), FakeCode: genFakeCode(opA, opRx(2))},
+	{Code: 0x4B, Bytes: 1, Cycles: 1, Mnemonic: "ORL", Func: genALU(aluORL, opA, opRx(3)), FakeCode: genFakeCode(opA, opRx(3))},
+	{Code: 0x4C, Bytes: 1, Cycles: 1, Mnemonic: "ORL", Func: genALU(aluORL, opA, opRx(4)), FakeCode: genFakeCode(opA, opRx(4))},
+	{Code: 0x4D, Bytes: 1, Cycles: 1, Mnemonic: "ORL", Func: genALU(aluORL, opA, opRx(5)), FakeCode: genFakeCode(opA, opRx(5))},
+	{Code: 0x4E, Bytes: 1, Cycles: 1, Mnemonic: "ORL", Func: genALU(aluORL, opA, opRx(6)), FakeCode: genFakeCode(opA, opRx(6))},
+	{Code: 0x4F, Bytes: 1, Cycles: 1, Mnemonic: "ORL", Func: genALU(aluORL, opA, opRx(7)), FakeCode: genFakeCode(opA, opRx(7))},
+	{Code: 0x50, Bytes: 2, Cycles: 2, Mnemonic: "JNC", Func: genJcond(func(m *Machine) bool { return !m.carry() }), FakeCode: fakeJcond},
+	{Code: 0x51, Bytes: 2, Cycles: 2, Mnemonic: "ACALL", Func: genACALL(2), FakeCode: genAddr11FakeCode(2)},
+	{Code: 0x52, Bytes: 2, Cycles: 1, Mnemonic: "ANL", Func: genALU(aluANL, opDirect(1), opA), FakeCode: genFakeCode(opDirect(1), opA)},
+	{Code: 0x53, Bytes: 3, Cycles: 2, Mnemonic: "ANL", Func: genALU(aluANL, opDirect(1), opImmed(2)), FakeCode: genFakeCode(opDirect(1), opImmed(2))},
+	{Code: 0x54, Bytes: 2, Cycles: 1, Mnemonic: "ANL", Func: genALU(aluANL, opA, opImmed(1)), FakeCode: genFakeCode(opA, opImmed(1))},
+	{Code: 0x55, Bytes: 2, Cycles: 1, Mnemonic: "ANL", Func: genALU(aluANL, opA, opDirect(1)), FakeCode: genFakeCode(opA, opDirect(1))},
+	{Code: 0x56, Bytes: 1, Cycles: 1, Mnemonic: "ANL", Func: genALU(aluANL, opA, opIndirect(0)), FakeCode: genFakeCode(opA, opIndirect(0))},
+	{Code: 0x57, Bytes: 1, Cycles: 1, Mnemonic: "ANL", Func: genALU(aluANL, opA, opIndirect(1)), FakeCode: genFakeCode(opA, opIndirect(1))},
+	{Code: 0x58, Bytes: 1, Cycles: 1, Mnemonic: "ANL", Func: genALU(aluANL, opA, opRx(0)), FakeCode: genFakeCode(opA, opRx(0))},
+	{Code: 0x59, Bytes: 1, Cycles: 1, Mnemonic: "ANL", Func: genALU(aluANL, opA, opRx(1)), FakeCode: genFakeCode(opA, opRx(1))},
+	{Code: 0x5A, Bytes: 1, Cycles: 1, Mnemonic: "ANL", Func: genALU(aluANL, opA, opRx(2)), FakeCode: genFakeCode(opA, opRx(2))},
+	{Code: 0x5B, Bytes: 1, Cycles: 1, Mnemonic: "ANL", Func: genALU(aluANL, opA, opRx(3)), FakeCode: genFakeCode(opA, opRx(3))},
+	{Code: 0x5C, Bytes: 1, Cycles: 1, Mnemonic: "ANL", Func: genALU(aluANL, opA, opRx(4)), FakeCode: genFakeCode(opA, opRx(4))},
+	{Code: 0x5D, Bytes: 1, Cycles: 1, Mnemonic: "ANL", Func: genALU(aluANL, opA, opRx(5)), FakeCode: genFakeCode(opA, opRx(5))},
+	{Code: 0x5E, Bytes: 1, Cycles: 1, Mnemonic: "ANL", Func: genALU(aluANL, opA, opRx(6)), FakeCode: genFakeCode(opA, opRx(6))},
+	{Code: 0x5F, Bytes: 1, Cycles: 1, Mnemonic: "ANL", Func: genALU(aluANL, opA, opRx(7)), FakeCode: genFakeCode(opA, opRx(7))},
+	{Code: 0x60, Bytes: 2, Cycles: 2, Mnemonic: "JZ", Func: genJcond(func(m *Machine) bool { return m.ReadDATA(ACC) == 0 }), FakeCode: fakeJcond},
+	{Code: 0x61, Bytes: 2, Cycles: 2, Mnemonic: "AJMP", Func: genAJMP(3), FakeCode: genAddr11FakeCode(3)},
+	{Code: 0x62, Bytes: 2, Cycles: 1, Mnemonic: "XRL", Func: genALU(aluXRL, opDirect(1), opA), FakeCode: genFakeCode(opDirect(1), opA)},
+	{Code: 0x63, Bytes: 3, Cycles: 2, Mnemonic: "XRL", Func: genALU(aluXRL, opDirect(1), opImmed(2)), FakeCode: genFakeCode(opDirect(1), opImmed(2))},
+	{Code: 0x64, Bytes: 2, Cycles: 1, Mnemonic: "XRL", Func: genALU(aluXRL, opA, opImmed(1)), FakeCode: genFakeCode(opA, opImmed(1))},
+	{Code: 0x65, Bytes: 2, Cycles: 1, Mnemonic: "XRL", Func: genALU(aluXRL, opA, opDirect(1)), FakeCode: genFakeCode(opA, opDirect(1))},
+	{Code: 0x66, Bytes: 1, Cycles: 1, Mnemonic: "XRL", Func: genALU(aluXRL, opA, opIndirect(0)), FakeCode: genFakeCode(opA, opIndirect(0))},
+	{Code: 0x67, Bytes: 1, Cycles: 1, Mnemonic: "XRL", Func: genALU(aluXRL, opA, opIndirect(1)), FakeCode: genFakeCode(opA, opIndirect(1))},
+	{Code: 0x68, Bytes: 1, Cycles: 1, Mnemonic: "XRL", Func: genALU(aluXRL, opA, opRx(0)), FakeCode: genFakeCode(opA, opRx(0))},
+	{Code: 0x69, Bytes: 1, Cycles: 1, Mnemonic: "XRL", Func: genALU(aluXRL, opA, opRx(1)), FakeCode: genFakeCode(opA, opRx(1))},
+	{Code: 0x6A, Bytes: 1, Cycles: 1, Mnemonic: "XRL", Func: genALU(aluXRL, opA, opRx(2)), FakeCode: genFakeCode(opA, opRx(2))},
+	{Code: 0x6B, Bytes: 1, Cycles: 1, Mnemonic: "XRL", Func: genALU(aluXRL, opA, opRx(3)), FakeCode: genFakeCode(opA, opRx(3))},
+	{Code: 0x6C, Bytes: 1, Cycles: 1, Mnemonic: "XRL", Func: genALU(aluXRL, opA, opRx(4)), FakeCode: genFakeCode(opA, opRx(4))},
+	{Code: 0x6D, Bytes: 1, Cycles: 1, Mnemonic: "XRL", Func: genALU(aluXRL, opA, opRx(5)), FakeCode: genFakeCode(opA, opRx(5))},
+	{Code: 0x6E, Bytes: 1, Cycles: 1, Mnemonic: "XRL", Func: genALU(aluXRL, opA, opRx(6)), FakeCode: genFakeCode(opA, opRx(6))},
+	{Code: 0x6F, Bytes: 1, Cycles: 1, Mnemonic: "XRL", Func: genALU(aluXRL, opA, opRx(7)), FakeCode: genFakeCode(opA, opRx(7))},
+	{Code: 0x70, Bytes: 2, Cycles: 2, Mnemonic: "JNZ", Func: genJcond(func(m *Machine) bool { return m.ReadDATA(ACC) != 0 }), FakeCode: fakeJcond},
+	{Code: 0x71, Bytes: 2, Cycles: 2, Mnemonic: "ACALL", Func: genACALL(3), FakeCode: genAddr11FakeCode(3)},
+	{Code: 0x72, Bytes: 2, Cycles: 2, Mnemonic: "ORL", Func: genCarryBit(func(c, b bool) bool { return c || b }, false), FakeCode: genCarryBitFakeCode(false)},
+	{Code: 0x73, Bytes: 1, Cycles: 2, Mnemonic: "JMP", Func: func(m *Machine) {
+		// JMP @A+DPTR
+		m.PC = uint(uint16(m.ReadDATA(ACC)) + m.ReadDPTR())
+	}, FakeCode: fakeString("@A+DPTR")},
+	{Code: 0x74, Bytes: 2, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opA, opImmed(1)), FakeCode: genFakeCode(opA, opImmed(1))},
+	{Code: 0x75, Bytes: 3, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opDirect(1), opImmed(2)), FakeCode: genFakeCode(opDirect(1), opImmed(2))},
+	{Code: 0x76, Bytes: 2, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opIndirect(0), opImmed(1)), FakeCode: genFakeCode(opIndirect(0), opImmed(1))},
+	{Code: 0x77, Bytes: 2, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opIndirect(1), opImmed(1)), FakeCode: genFakeCode(opIndirect(1), opImmed(1))},
+	{Code: 0x78, Bytes: 2, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opRx(0), opImmed(1)), FakeCode: genFakeCode(opRx(0), opImmed(1))},
+	{Code: 0x79, Bytes: 2, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opRx(1), opImmed(1)), FakeCode: genFakeCode(opRx(1), opImmed(1))},
+	{Code: 0x7A, Bytes: 2, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opRx(2), opImmed(1)), FakeCode: genFakeCode(opRx(2), opImmed(1))},
+	{Code: 0x7B, Bytes: 2, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opRx(3), opImmed(1)), FakeCode: genFakeCode(opRx(3), opImmed(1))},
+	{Code: 0x7C, Bytes: 2, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opRx(4), opImmed(1)), FakeCode: genFakeCode(opRx(4), opImmed(1))},
+	{Code: 0x7D, Bytes: 2, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opRx(5), opImmed(1)), FakeCode: genFakeCode(opRx(5), opImmed(1))},
+	{Code: 0x7E, Bytes: 2, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opRx(6), opImmed(1)), FakeCode: genFakeCode(opRx(6), opImmed(1))},
+	{Code: 0x7F, Bytes: 2, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opRx(7), opImmed(1)), FakeCode: genFakeCode(opRx(7), opImmed(1))},
+	{Code: 0x80, Bytes: 2, Cycles: 2, Mnemonic: "SJMP", Func: func(m *Machine) {
+		m.PC = relAddr(m.PC+2, m.ReadCODE(m.PC+1))
+	}, FakeCode: fakeJcond},
+	{Code: 0x81, Bytes: 2, Cycles: 2, Mnemonic: "AJMP", Func: genAJMP(4), FakeCode: genAddr11FakeCode(4)},
+	{Code: 0x82, Bytes: 2, Cycles: 2, Mnemonic: "ANL", Func: genCarryBit(func(c, b bool) bool { return c && b }, false), FakeCode: genCarryBitFakeCode(false)},
+	{Code: 0x83, Bytes: 1, Cycles: 2, Mnemonic: "MOVC", Func: func(m *Machine) {
+		// MOVC A, @A+PC
+		m.PC++
+		m.WriteDATA(ACC, m.readCodeData(uint(uint16(m.PC)+uint16(m.ReadDATA(ACC)))))
+	}, FakeCode: fakeString("A @A+PC")},
+	{Code: 0x84, Bytes: 1, Cycles: 4, Mnemonic: "DIV", Func: func(m *Machine) {
+		a := m.ReadDATA(ACC)
+		b := m.ReadDATA(B)
+		// CY always cleared, OV set on divide by zero
+		m.SetFlag(FlagCY, false)
+		m.SetFlag(FlagOV, b == 0)
+		if b != 0 {
+			m.WriteDATA(ACC, a/b)
+			m.WriteDATA(B, a%b)
 		}
-		return fmt.Sprintf("C:%d(%04X)", offset, pc)
-	}},
-
-	{Code: 0x88, Bytes: 2, Mnemonic: "MOV", Func: genMOVdirectRx(0)},
-	{Code: 0x89, Bytes: 2, Mnemonic: "MOV", Func: genMOVdirectRx(1)},
-	{Code: 0x8A, Bytes: 2, Mnemonic: "MOV", Func: genMOVdirectRx(2)},
-	{Code: 0x8B, Bytes: 2, Mnemonic: "MOV", Func: genMOVdirectRx(3)},
-	{Code: 0x8C, Bytes: 2, Mnemonic: "MOV", Func: genMOVdirectRx(4)},
-	{Code: 0x8D, Bytes: 2, Mnemonic: "MOV", Func: genMOVdirectRx(5)},
-	{Code: 0x8E, Bytes: 2, Mnemonic: "MOV", Func: genMOVdirectRx(6)},
-	{Code: 0x8F, Bytes: 2, Mnemonic: "MOV", Func: genMOVdirectRx(7)},
-	{Code: 0x90, Bytes: 3, Mnemonic: "MOV", Func: func(m *Machine) {
+		m.PC++
+	}, FakeCode: genFakeCode(opA, opB)},
+	{Code: 0x85, Bytes: 3, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opDirect(2), opDirect(1)), FakeCode: genFakeCode(opDirect(2), opDirect(1))},
+	{Code: 0x86, Bytes: 2, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opDirect(1), opIndirect(0)), FakeCode: genFakeCode(opDirect(1), opIndirect(0))},
+	{Code: 0x87, Bytes: 2, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opDirect(1), opIndirect(1)), FakeCode: genFakeCode(opDirect(1), opIndirect(1))},
+	{Code: 0x88, Bytes: 2, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opDirect(1), opRx(0)), FakeCode: genFakeCode(opDirect(1), opRx(0))},
+	{Code: 0x89, Bytes: 2, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opDirect(1), opRx(1)), FakeCode: genFakeCode(opDirect(1), opRx(1))},
+	{Code: 0x8A, Bytes: 2, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opDirect(1), opRx(2)), FakeCode: genFakeCode(opDirect(1), opRx(2))},
+	{Code: 0x8B, Bytes: 2, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opDirect(1), opRx(3)), FakeCode: genFakeCode(opDirect(1), opRx(3))},
+	{Code: 0x8C, Bytes: 2, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opDirect(1), opRx(4)), FakeCode: genFakeCode(opDirect(1), opRx(4))},
+	{Code: 0x8D, Bytes: 2, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opDirect(1), opRx(5)), FakeCode: genFakeCode(opDirect(1), opRx(5))},
+	{Code: 0x8E, Bytes: 2, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opDirect(1), opRx(6)), FakeCode: genFakeCode(opDirect(1), opRx(6))},
+	{Code: 0x8F, Bytes: 2, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opDirect(1), opRx(7)), FakeCode: genFakeCode(opDirect(1), opRx(7))},
+	{Code: 0x90, Bytes: 3, Cycles: 2, Mnemonic: "MOV", Func: func(m *Machine) {
 		// MOV	DPTR, #immed
-		m.WriteDATA(DPH, m.ROM[m.PC+1])
-		m.WriteDATA(DPL, m.ROM[m.PC+2])
+		m.WriteDATA(DPH, m.ReadCODE(m.PC+1))
+		m.WriteDATA(DPL, m.ReadCODE(m.PC+2))
 		m.PC += 3
-	}, FakeCode: func(m Machine, pc uint) string {
-		return fmt.Sprintf("DPTR #0x%02X%02X", m.ROM[pc+1], m.ROM[pc+2])
+	}, FakeCode: func(m *Machine, pc uint) string {
+		return fmt.Sprintf("DPTR #0x%02X%02X", m.ReadCODE(pc+1), m.ReadCODE(pc+2))
 	}},
-
-	{Code: 0xC0, Bytes: 2, Mnemonic: "PUSH", Func: func(m *Machine) {
+	{Code: 0x91, Bytes: 2, Cycles: 2, Mnemonic: "ACALL", Func: genACALL(4), FakeCode: genAddr11FakeCode(4)},
+	{Code: 0x92, Bytes: 2, Cycles: 2, Mnemonic: "MOV", Func: func(m *Machine) {
+		// MOV bit, C
+		m.WriteBit(m.ReadCODE(m.PC+1), m.carry())
+		m.PC += 2
+	}, FakeCode: func(m *Machine, pc uint) string {
+		return fmt.Sprintf("%s C", fakeBit(m, m.ReadCODE(pc+1)))
+	}},
+	{Code: 0x93, Bytes: 1, Cycles: 2, Mnemonic: "MOVC", Func: func(m *Machine) {
+		// MOVC A, @A+DPTR
+		m.WriteDATA(ACC, m.readCodeData(uint(m.ReadDPTR()+uint16(m.ReadDATA(ACC)))))
+		m.PC++
+	}, FakeCode: fakeString("A @A+DPTR")},
+	{Code: 0x94, Bytes: 2, Cycles: 1, Mnemonic: "SUBB", Func: genALU(aluSUBB, opA, opImmed(1)), FakeCode: genFakeCode(opA, opImmed(1))},
+	{Code: 0x95, Bytes: 2, Cycles: 1, Mnemonic: "SUBB", Func: genALU(aluSUBB, opA, opDirect(1)), FakeCode: genFakeCode(opA, opDirect(1))},
+	{Code: 0x96, Bytes: 1, Cycles: 1, Mnemonic: "SUBB", Func: genALU(aluSUBB, opA, opIndirect(0)), FakeCode: genFakeCode(opA, opIndirect(0))},
+	{Code: 0x97, Bytes: 1, Cycles: 1, Mnemonic: "SUBB", Func: genALU(aluSUBB, opA, opIndirect(1)), FakeCode: genFakeCode(opA, opIndirect(1))},
+	{Code: 0x98, Bytes: 1, Cycles: 1, Mnemonic: "SUBB", Func: genALU(aluSUBB, opA, opRx(0)), FakeCode: genFakeCode(opA, opRx(0))},
+	{Code: 0x99, Bytes: 1, Cycles: 1, Mnemonic: "SUBB", Func: genALU(aluSUBB, opA, opRx(1)), FakeCode: genFakeCode(opA, opRx(1))},
+	{Code: 0x9A, Bytes: 1, Cycles: 1, Mnemonic: "SUBB", Func: genALU(aluSUBB, opA, opRx(2)), FakeCode: genFakeCode(opA, opRx(2))},
+	{Code: 0x9B, Bytes: 1, Cycles: 1, Mnemonic: "SUBB", Func: genALU(aluSUBB, opA, opRx(3)), FakeCode: genFakeCode(opA, opRx(3))},
+	{Code: 0x9C, Bytes: 1, Cycles: 1, Mnemonic: "SUBB", Func: genALU(aluSUBB, opA, opRx(4)), FakeCode: genFakeCode(opA, opRx(4))},
+	{Code: 0x9D, Bytes: 1, Cycles: 1, Mnemonic: "SUBB", Func: genALU(aluSUBB, opA, opRx(5)), FakeCode: genFakeCode(opA, opRx(5))},
+	{Code: 0x9E, Bytes: 1, Cycles: 1, Mnemonic: "SUBB", Func: genALU(aluSUBB, opA, opRx(6)), FakeCode: genFakeCode(opA, opRx(6))},
+	{Code: 0x9F, Bytes: 1, Cycles: 1, Mnemonic: "SUBB", Func: genALU(aluSUBB, opA, opRx(7)), FakeCode: genFakeCode(opA, opRx(7))},
+	{Code: 0xA0, Bytes: 2, Cycles: 2, Mnemonic: "ORL", Func: genCarryBit(func(c, b bool) bool { return c || b }, true), FakeCode: genCarryBitFakeCode(true)},
+	{Code: 0xA1, Bytes: 2, Cycles: 2, Mnemonic: "AJMP", Func: genAJMP(5), FakeCode: genAddr11FakeCode(5)},
+	{Code: 0xA2, Bytes: 2, Cycles: 1, Mnemonic: "MOV", Func: genCarryBit(func(c, b bool) bool { return b }, false), FakeCode: genCarryBitFakeCode(false)},
+	{Code: 0xA3, Bytes: 1, Cycles: 2, Mnemonic: "INC", Func: func(m *Machine) {
+		m.WriteDPTR(m.ReadDPTR() + 1)
+		m.PC++
+	}, FakeCode: fakeString("DPTR")},
+	{Code: 0xA4, Bytes: 1, Cycles: 4, Mnemonic: "MUL", Func: func(m *Machine) {
+		r := uint16(m.ReadDATA(ACC)) * uint16(m.ReadDATA(B))
+		// CY always cleared, OV set if product greater than 0xFF
+		m.SetFlag(FlagCY, false)
+		m.SetFlag(FlagOV, r > 0xFF)
+		m.WriteDATA(ACC, uint8(r))
+		m.WriteDATA(B, uint8(r>>8))
+		m.PC++
+	}, FakeCode: genFakeCode(opA, opB)},
+	{Code: 0xA6, Bytes: 2, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opIndirect(0), opDirect(1)), FakeCode: genFakeCode(opIndirect(0), opDirect(1))},
+	{Code: 0xA7, Bytes: 2, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opIndirect(1), opDirect(1)), FakeCode: genFakeCode(opIndirect(1), opDirect(1))},
+	{Code: 0xA8, Bytes: 2, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opRx(0), opDirect(1)), FakeCode: genFakeCode(opRx(0), opDirect(1))},
+	{Code: 0xA9, Bytes: 2, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opRx(1), opDirect(1)), FakeCode: genFakeCode(opRx(1), opDirect(1))},
+	{Code: 0xAA, Bytes: 2, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opRx(2), opDirect(1)), FakeCode: genFakeCode(opRx(2), opDirect(1))},
+	{Code: 0xAB, Bytes: 2, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opRx(3), opDirect(1)), FakeCode: genFakeCode(opRx(3), opDirect(1))},
+	{Code: 0xAC, Bytes: 2, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opRx(4), opDirect(1)), FakeCode: genFakeCode(opRx(4), opDirect(1))},
+	{Code: 0xAD, Bytes: 2, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opRx(5), opDirect(1)), FakeCode: genFakeCode(opRx(5), opDirect(1))},
+	{Code: 0xAE, Bytes: 2, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opRx(6), opDirect(1)), FakeCode: genFakeCode(opRx(6), opDirect(1))},
+	{Code: 0xAF, Bytes: 2, Cycles: 2, Mnemonic: "MOV", Func: genMOV(opRx(7), opDirect(1)), FakeCode: genFakeCode(opRx(7), opDirect(1))},
+	{Code: 0xB0, Bytes: 2, Cycles: 2, Mnemonic: "ANL", Func: genCarryBit(func(c, b bool) bool { return c && b }, true), FakeCode: genCarryBitFakeCode(true)},
+	{Code: 0xB1, Bytes: 2, Cycles: 2, Mnemonic: "ACALL", Func: genACALL(5), FakeCode: genAddr11FakeCode(5)},
+	{Code: 0xB2, Bytes: 2, Cycles: 1, Mnemonic: "CPL", Func: genBitOp(func(m *Machine, v bool) bool { return !v }), FakeCode: fakeBitOp},
+	{Code: 0xB3, Bytes: 1, Cycles: 1, Mnemonic: "CPL", Func: genCarryOp(func(c bool) bool { return !c }), FakeCode: fakeString("C")},
+	{Code: 0xB4, Bytes: 3, Cycles: 2, Mnemonic: "CJNE", Func: genCJNE(opA, opImmed(1)), FakeCode: genCJNEFakeCode(opA, opImmed(1))},
+	{Code: 0xB5, Bytes: 3, Cycles: 2, Mnemonic: "CJNE", Func: genCJNE(opA, opDirect(1)), FakeCode: genCJNEFakeCode(opA, opDirect(1))},
+	{Code: 0xB6, Bytes: 3, Cycles: 2, Mnemonic: "CJNE", Func: genCJNE(opIndirect(0), opImmed(1)), FakeCode: genCJNEFakeCode(opIndirect(0), opImmed(1))},
+	{Code: 0xB7, Bytes: 3, Cycles: 2, Mnemonic: "CJNE", Func: genCJNE(opIndirect(1), opImmed(1)), FakeCode: genCJNEFakeCode(opIndirect(1), opImmed(1))},
+	{Code: 0xB8, Bytes: 3, Cycles: 2, Mnemonic: "CJNE", Func: genCJNE(opRx(0), opImmed(1)), FakeCode: genCJNEFakeCode(opRx(0), opImmed(1))},
+	{Code: 0xB9, Bytes: 3, Cycles: 2, Mnemonic: "CJNE", Func: genCJNE(opRx(1), opImmed(1)), FakeCode: genCJNEFakeCode(opRx(1), opImmed(1))},
+	{Code: 0xBA, Bytes: 3, Cycles: 2, Mnemonic: "CJNE", Func: genCJNE(opRx(2), opImmed(1)), FakeCode: genCJNEFakeCode(opRx(2), opImmed(1))},
+	{Code: 0xBB, Bytes: 3, Cycles: 2, Mnemonic: "CJNE", Func: genCJNE(opRx(3), opImmed(1)), FakeCode: genCJNEFakeCode(opRx(3), opImmed(1))},
+	{Code: 0xBC, Bytes: 3, Cycles: 2, Mnemonic: "CJNE", Func: genCJNE(opRx(4), opImmed(1)), FakeCode: genCJNEFakeCode(opRx(4), opImmed(1))},
+	{Code: 0xBD, Bytes: 3, Cycles: 2, Mnemonic: "CJNE", Func: genCJNE(opRx(5), opImmed(1)), FakeCode: genCJNEFakeCode(opRx(5), opImmed(1))},
+	{Code: 0xBE, Bytes: 3, Cycles: 2, Mnemonic: "CJNE", Func: genCJNE(opRx(6), opImmed(1)), FakeCode: genCJNEFakeCode(opRx(6), opImmed(1))},
+	{Code: 0xBF, Bytes: 3, Cycles: 2, Mnemonic: "CJNE", Func: genCJNE(opRx(7), opImmed(1)), FakeCode: genCJNEFakeCode(opRx(7), opImmed(1))},
+	{Code: 0xC0, Bytes: 2, Cycles: 2, Mnemonic: "PUSH", Func: func(m *Machine) {
 		// SP = SP + 1
 		// (SP) = (direct)
-		m.WriteDATA(SP, m.ReadDATA(SP)+1)
-		m.WriteDATA(m.ReadDATA(SP), m.ROM[m.PC+1])
+		m.push(opDirect(1).read(m))
 		m.PC += 2
-	}, FakeCode: func(m Machine, pc uint) string {
-		if r := FindRegByAddr(m.ROM[pc+1], m.regDefines); r != nil {
-			return fmt.Sprintf("%s(0x%02X)", r.Name, r.Addr)
-		}
-		return fmt.Sprintf("0x%02X", m.ROM[pc+1])
-	}},
-
-	{Code: 0xD0, Bytes: 2, Mnemonic: "POP", Func: func(m *Machine) {
+	}, FakeCode: genFakeCode(opDirect(1))},
+	{Code: 0xC1, Bytes: 2, Cycles: 2, Mnemonic: "AJMP", Func: genAJMP(6), FakeCode: genAddr11FakeCode(6)},
+	{Code: 0xC2, Bytes: 2, Cycles: 1, Mnemonic: "CLR", Func: genBitOp(func(m *Machine, v bool) bool { return false }), FakeCode: fakeBitOp},
+	{Code: 0xC3, Bytes: 1, Cycles: 1, Mnemonic: "CLR", Func: genCarryOp(func(c bool) bool { return false }), FakeCode: fakeString("C")},
+	{Code: 0xC4, Bytes: 1, Cycles: 1, Mnemonic: "SWAP", Func: genUnary(aluSWAP, opA), FakeCode: genFakeCode(opA)},
+	{Code: 0xC5, Bytes: 2, Cycles: 1, Mnemonic: "XCH", Func: genXCH(opDirect(1)), FakeCode: genFakeCode(opA, opDirect(1))},
+	{Code: 0xC6, Bytes: 1, Cycles: 1, Mnemonic: "XCH", Func: genXCH(opIndirect(0)), FakeCode: genFakeCode(opA, opIndirect(0))},
+	{Code: 0xC7, Bytes: 1, Cycles: 1, Mnemonic: "XCH", Func: genXCH(opIndirect(1)), FakeCode: genFakeCode(opA, opIndirect(1))},
+	{Code: 0xC8, Bytes: 1, Cycles: 1, Mnemonic: "XCH", Func: genXCH(opRx(0)), FakeCode: genFakeCode(opA, opRx(0))},
+	{Code: 0xC9, Bytes: 1, Cycles: 1, Mnemonic: "XCH", Func: genXCH(opRx(1)), FakeCode: genFakeCode(opA, opRx(1))},
+	{Code: 0xCA, Bytes: 1, Cycles: 1, Mnemonic: "XCH", Func: genXCH(opRx(2)), FakeCode: genFakeCode(opA, opRx(2))},
+	{Code: 0xCB, Bytes: 1, Cycles: 1, Mnemonic: "XCH", Func: genXCH(opRx(3)), FakeCode: genFakeCode(opA, opRx(3))},
+	{Code: 0xCC, Bytes: 1, Cycles: 1, Mnemonic: "XCH", Func: genXCH(opRx(4)), FakeCode: genFakeCode(opA, opRx(4))},
+	{Code: 0xCD, Bytes: 1, Cycles: 1, Mnemonic: "XCH", Func: genXCH(opRx(5)), FakeCode: genFakeCode(opA, opRx(5))},
+	{Code: 0xCE, Bytes: 1, Cycles: 1, Mnemonic: "XCH", Func: genXCH(opRx(6)), FakeCode: genFakeCode(opA, opRx(6))},
+	{Code: 0xCF, Bytes: 1, Cycles: 1, Mnemonic: "XCH", Func: genXCH(opRx(7)), FakeCode: genFakeCode(opA, opRx(7))},
+	{Code: 0xD0, Bytes: 2, Cycles: 2, Mnemonic: "POP", Func: func(m *Machine) {
 		// (direct) = (SP)
 		// SP = SP - 1
-		m.WriteDATA(m.ROM[m.PC+1], m.ReadDATA(m.ReadDATA(SP)))
-		m.WriteDATA(SP, m.ReadDATA(SP)-1)
+		opDirect(1).write(m, m.pop())
 		m.PC += 2
-	}, FakeCode: func(m Machine, pc uint) string {
-		if r := FindRegByAddr(m.ROM[pc+1], m.regDefines); r != nil {
-			return fmt.Sprintf("%s(0x%02X)", r.Name, r.Addr)
-		}
-		return fmt.Sprintf("0x%02X", m.ROM[pc+1])
-	}},
-
-	{Code: 0xD8, Bytes: 2, Mnemonic: "DJNZ", Func: genDJNZRxOffset(0), FakeCode: genDJNZRxOffsetFakeCode(0)},
-	{Code: 0xD9, Bytes: 2, Mnemonic: "DJNZ", Func: genDJNZRxOffset(1), FakeCode: genDJNZRxOffsetFakeCode(1)},
-	{Code: 0xDA, Bytes: 2, Mnemonic: "DJNZ", Func: genDJNZRxOffset(2), FakeCode: genDJNZRxOffsetFakeCode(2)},
-	{Code: 0xDB, Bytes: 2, Mnemonic: "DJNZ", Func: genDJNZRxOffset(3), FakeCode: genDJNZRxOffsetFakeCode(3)},
-	{Code: 0xDC, Bytes: 2, Mnemonic: "DJNZ", Func: genDJNZRxOffset(4), FakeCode: genDJNZRxOffsetFakeCode(4)},
-	{Code: 0xDD, Bytes: 2, Mnemonic: "DJNZ", Func: genDJNZRxOffset(5), FakeCode: genDJNZRxOffsetFakeCode(5)},
-	{Code: 0xDE, Bytes: 2, Mnemonic: "DJNZ", Func: genDJNZRxOffset(6), FakeCode: genDJNZRxOffsetFakeCode(6)},
-	{Code: 0xDF, Bytes: 2, Mnemonic: "DJNZ", Func: genDJNZRxOffset(7), FakeCode: genDJNZRxOffsetFakeCode(7)},
-	{Code: 0xE0, Bytes: 1, Mnemonic: "MOVX", Func: func(m *Machine) {
+	}, FakeCode: genFakeCode(opDirect(1))},
+	{Code: 0xD1, Bytes: 2, Cycles: 2, Mnemonic: "ACALL", Func: genACALL(6), FakeCode: genAddr11FakeCode(6)},
+	{Code: 0xD2, Bytes: 2, Cycles: 1, Mnemonic: "SETB", Func: genBitOp(func(m *Machine, v bool) bool { return true }), FakeCode: fakeBitOp},
+	{Code: 0xD3, Bytes: 1, Cycles: 1, Mnemonic: "SETB", Func: genCarryOp(func(c bool) bool { return true }), FakeCode: fakeString("C")},
+	{Code: 0xD4, Bytes: 1, Cycles: 1, Mnemonic: "DA", Func: genUnary(aluDA, opA), FakeCode: genFakeCode(opA)},
+	{Code: 0xD5, Bytes: 3, Cycles: 2, Mnemonic: "DJNZ", Func: genDJNZ(opDirect(1)), FakeCode: genDJNZFakeCode(opDirect(1))},
+	{Code: 0xD6, Bytes: 1, Cycles: 1, Mnemonic: "XCHD", Func: genXCHD(0), FakeCode: genFakeCode(opA, opIndirect(0))},
+	{Code: 0xD7, Bytes: 1, Cycles: 1, Mnemonic: "XCHD", Func: genXCHD(1), FakeCode: genFakeCode(opA, opIndirect(1))},
+	{Code: 0xD8, Bytes: 2, Cycles: 2, Mnemonic: "DJNZ", Func: genDJNZ(opRx(0)), FakeCode: genDJNZFakeCode(opRx(0))},
+	{Code: 0xD9, Bytes: 2, Cycles: 2, Mnemonic: "DJNZ", Func: genDJNZ(opRx(1)), FakeCode: genDJNZFakeCode(opRx(1))},
+	{Code: 0xDA, Bytes: 2, Cycles: 2, Mnemonic: "DJNZ", Func: genDJNZ(opRx(2)), FakeCode: genDJNZFakeCode(opRx(2))},
+	{Code: 0xDB, Bytes: 2, Cycles: 2, Mnemonic: "DJNZ", Func: genDJNZ(opRx(3)), FakeCode: genDJNZFakeCode(opRx(3))},
+	{Code: 0xDC, Bytes: 2, Cycles: 2, Mnemonic: "DJNZ", Func: genDJNZ(opRx(4)), FakeCode: genDJNZFakeCode(opRx(4))},
+	{Code: 0xDD, Bytes: 2, Cycles: 2, Mnemonic: "DJNZ", Func: genDJNZ(opRx(5)), FakeCode: genDJNZFakeCode(opRx(5))},
+	{Code: 0xDE, Bytes: 2, Cycles: 2, Mnemonic: "DJNZ", Func: genDJNZ(opRx(6)), FakeCode: genDJNZFakeCode(opRx(6))},
+	{Code: 0xDF, Bytes: 2, Cycles: 2, Mnemonic: "DJNZ", Func: genDJNZ(opRx(7)), FakeCode: genDJNZFakeCode(opRx(7))},
+	{Code: 0xE0, Bytes: 1, Cycles: 2, Mnemonic: "MOVX", Func: func(m *Machine) {
 		// MOVX	A, @DPTR
-		dptrAddr := uint16(m.ReadDATA(DPH))>>8 | uint16(m.ReadDATA(DPL))
-		m.WriteDATA(ACC, m.XDATA[dptrAddr])
-		m.PC++
-	}, FakeCode: func(m Machine, pc uint) string { return fmt.Sprintf("A @DPTR") }},
-	{0xE4, 1, "CLR", func(m *Machine) {
-		m.WriteDATA(ACC, 0)
-		m.PC += 2
-	}, func(m Machine, pc uint) string { return "A" }},
-
-	{Code: 0xE8, Bytes: 1, Mnemonic: "MOV", Func: genMOVARx(0)},
-	{Code: 0xE9, Bytes: 1, Mnemonic: "MOV", Func: genMOVARx(1)},
-	{Code: 0xEA, Bytes: 1, Mnemonic: "MOV", Func: genMOVARx(2)},
-	{Code: 0xEB, Bytes: 1, Mnemonic: "MOV", Func: genMOVARx(3)},
-	{Code: 0xEC, Bytes: 1, Mnemonic: "MOV", Func: genMOVARx(4)},
-	{Code: 0xED, Bytes: 1, Mnemonic: "MOV", Func: genMOVARx(5)},
-	{Code: 0xEE, Bytes: 1, Mnemonic: "MOV", Func: genMOVARx(6)},
-	{Code: 0xEF, Bytes: 1, Mnemonic: "MOV", Func: genMOVARx(7)},
-	{Code: 0xF0, Bytes: 1, Mnemonic: "MOV", Func: func(m *Machine) {
-		// MOV @DPTR, A
-		addr := uint16(m.DATA[DPH])<<8 | uint16(m.DATA[DPL])
-		m.WriteDATA(uint8(addr), ACC)
-		m.PC++
-	}, FakeCode: func(m Machine, pc uint) string { return fmt.Sprintf("@DPTR A") }},
-	{Code: 0xF6, Bytes: 1, Mnemonic: "MOV", Func: func(m *Machine) {
-		// 	MOV	@R0, A
-		m.WriteDATA(m.ReadRx(0), ACC)
+		m.WriteDATA(ACC, m.ReadXDATA(m.ReadDPTR()))
 		m.PC++
-	}, FakeCode: func(m Machine, pc uint) string { return fmt.Sprintf("@R0, A") }},
-	{Code: 0xF7, Bytes: 1, Mnemonic: "MOV", Func: func(m *Machine) {
-		// MOV	@R1, A
-		m.WriteDATA(m.ReadRx(1), ACC)
+	}, FakeCode: fakeString("A @DPTR")},
+	{Code: 0xE1, Bytes: 2, Cycles: 2, Mnemonic: "AJMP", Func: genAJMP(7), FakeCode: genAddr11FakeCode(7)},
+	{Code: 0xE2, Bytes: 1, Cycles: 2, Mnemonic: "MOVX", Func: genMOVXRead(0), FakeCode: genFakeCode(opA, opIndirect(0))},
+	{Code: 0xE3, Bytes: 1, Cycles: 2, Mnemonic: "MOVX", Func: genMOVXRead(1), FakeCode: genFakeCode(opA, opIndirect(1))},
+	{Code: 0xE4, Bytes: 1, Cycles: 1, Mnemonic: "CLR", Func: genUnary(aluCLR, opA), FakeCode: genFakeCode(opA)},
+	{Code: 0xE5, Bytes: 2, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opA, opDirect(1)), FakeCode: genFakeCode(opA, opDirect(1))},
+	{Code: 0xE6, Bytes: 1, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opA, opIndirect(0)), FakeCode: genFakeCode(opA, opIndirect(0))},
+	{Code: 0xE7, Bytes: 1, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opA, opIndirect(1)), FakeCode: genFakeCode(opA, opIndirect(1))},
+	{Code: 0xE8, Bytes: 1, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opA, opRx(0)), FakeCode: genFakeCode(opA, opRx(0))},
+	{Code: 0xE9, Bytes: 1, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opA, opRx(1)), FakeCode: genFakeCode(opA, opRx(1))},
+	{Code: 0xEA, Bytes: 1, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opA, opRx(2)), FakeCode: genFakeCode(opA, opRx(2))},
+	{Code: 0xEB, Bytes: 1, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opA, opRx(3)), FakeCode: genFakeCode(opA, opRx(3))},
+	{Code: 0xEC, Bytes: 1, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opA, opRx(4)), FakeCode: genFakeCode(opA, opRx(4))},
+	{Code: 0xED, Bytes: 1, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opA, opRx(5)), FakeCode: genFakeCode(opA, opRx(5))},
+	{Code: 0xEE, Bytes: 1, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opA, opRx(6)), FakeCode: genFakeCode(opA, opRx(6))},
+	{Code: 0xEF, Bytes: 1, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opA, opRx(7)), FakeCode: genFakeCode(opA, opRx(7))},
+	{Code: 0xF0, Bytes: 1, Cycles: 2, Mnemonic: "MOVX", Func: func(m *Machine) {
+		// MOVX @DPTR, A
+		m.WriteXDATA(m.ReadDPTR(), m.ReadDATA(ACC))
 		m.PC++
-	}, FakeCode: func(m Machine, pc uint) string { return fmt.Sprintf("@R1, A") }},
-	{Code: 0xF8, Bytes: 1, Mnemonic: "MOV", Func: genMOVRxA(0), FakeCode: genMOVRxAFakeCode(0)},
-	{Code: 0xF9, Bytes: 1, Mnemonic: "MOV", Func: genMOVRxA(1), FakeCode: genMOVRxAFakeCode(1)},
-	{Code: 0xFA, Bytes: 1, Mnemonic: "MOV", Func: genMOVRxA(2), FakeCode: genMOVRxAFakeCode(2)},
-	{Code: 0xFB, Bytes: 1, Mnemonic: "MOV", Func: genMOVRxA(3), FakeCode: genMOVRxAFakeCode(3)},
-	{Code: 0xFC, Bytes: 1, Mnemonic: "MOV", Func: genMOVRxA(4), FakeCode: genMOVRxAFakeCode(4)},
-	{Code: 0xFD, Bytes: 1, Mnemonic: "MOV", Func: genMOVRxA(5), FakeCode: genMOVRxAFakeCode(5)},
-	{Code: 0xFE, Bytes: 1, Mnemonic: "MOV", Func: genMOVRxA(6), FakeCode: genMOVRxAFakeCode(6)},
-	{Code: 0xFF, Bytes: 1, Mnemonic: "MOV", Func: genMOVRxA(7), FakeCode: genMOVRxAFakeCode(7)},
+	}, FakeCode: fakeString("@DPTR A")},
+	{Code: 0xF1, Bytes: 2, Cycles: 2, Mnemonic: "ACALL", Func: genACALL(7), FakeCode: genAddr11FakeCode(7)},
+	{Code: 0xF2, Bytes: 1, Cycles: 2, Mnemonic: "MOVX", Func: genMOVXWrite(0), FakeCode: genFakeCode(opIndirect(0), opA)},
+	{Code: 0xF3, Bytes: 1, Cycles: 2, Mnemonic: "MOVX", Func: genMOVXWrite(1), FakeCode: genFakeCode(opIndirect(1), opA)},
+	{Code: 0xF4, Bytes: 1, Cycles: 1, Mnemonic: "CPL", Func: genUnary(aluCPL, opA), FakeCode: genFakeCode(opA)},
+	{Code: 0xF5, Bytes: 2, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opDirect(1), opA), FakeCode: genFakeCode(opDirect(1), opA)},
+	{Code: 0xF6, Bytes: 1, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opIndirect(0), opA), FakeCode: genFakeCode(opIndirect(0), opA)},
+	{Code: 0xF7, Bytes: 1, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opIndirect(1), opA), FakeCode: genFakeCode(opIndirect(1), opA)},
+	{Code: 0xF8, Bytes: 1, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opRx(0), opA), FakeCode: genFakeCode(opRx(0), opA)},
+	{Code: 0xF9, Bytes: 1, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opRx(1), opA), FakeCode: genFakeCode(opRx(1), opA)},
+	{Code: 0xFA, Bytes: 1, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opRx(2), opA), FakeCode: genFakeCode(opRx(2), opA)},
+	{Code: 0xFB, Bytes: 1, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opRx(3), opA), FakeCode: genFakeCode(opRx(3), opA)},
+	{Code: 0xFC, Bytes: 1, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opRx(4), opA), FakeCode: genFakeCode(opRx(4), opA)},
+	{Code: 0xFD, Bytes: 1, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opRx(5), opA), FakeCode: genFakeCode(opRx(5), opA)},
+	{Code: 0xFE, Bytes: 1, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opRx(6), opA), FakeCode: genFakeCode(opRx(6), opA)},
+	{Code: 0xFF, Bytes: 1, Cycles: 1, Mnemonic: "MOV", Func: genMOV(opRx(7), opA), FakeCode: genFakeCode(opRx(7), opA)},
 }
 
 // FindINS find Instructions
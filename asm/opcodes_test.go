@@ -0,0 +1,67 @@
+package asm_test
+
+import (
+	"testing"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+func Test_Instructions(t *testing.T) {
+	for code := 0; code <= 0xFF; code++ {
+		ins, err := asm.FindINS(byte(code))
+		if code == 0xA5 {
+			// reserved
+			if err == nil {
+				t.Errorf("%02X should be unsupported", code)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("FindINS %02X: %s", code, err)
+			continue
+		}
+		if ins.Code != byte(code) || ins.Func == nil || ins.FakeCode == nil {
+			t.Errorf("bad instruction %02X %s", code, ins.Mnemonic)
+		}
+	}
+}
+
+func Test_Instructions_Run(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x75, 0x81, 0x30, // 0000: MOV SP, #0x30
+		0x74, 0x05, // 0003: MOV A, #0x05
+		0x90, 0x00, 0x20, // 0005: MOV DPTR, #0x0020
+		0x93,       // 0008: MOVC A, @A+DPTR
+		0xF5, 0x30, // 0009: MOV 0x30, A
+		0x75, 0xF0, 0x03, // 000B: MOV B, #0x03
+		0xA4,       // 000E: MUL AB
+		0x11, 0x19, // 000F: ACALL 0019
+		0xB4, 0x4E, 0xFE, // 0011: CJNE A, #0x4E, 0011
+		0xC5, 0x30, // 0014: XCH A, 0x30
+		0xC4,       // 0016: SWAP A
+		0x80, 0xFE, // 0017: SJMP 0017
+		0x24, 0x0F, // 0019: ADD A, #0x0F
+		0x22,                   // 001B: RET
+		0x00, 0x00, 0x00, 0x00, // 001C: padding
+		0x10, 0x11, 0x12, 0x13, 0x14, 0x15, // 0020: table
+	}
+
+	for i := 0; i < 100 && m.PC != 0x17; i++ {
+		m.Single()
+	}
+
+	if m.PC != 0x17 {
+		t.Fatalf("PC %04X not reach 0017", m.PC)
+	}
+	if m.SFR[asm.SP] != 0x30 {
+		t.Errorf("SP %02X != 30", m.SFR[asm.SP])
+	}
+	// MOVC table[5] = 0x15, 0x15*3 = 0x3F, 0x3F+0x0F = 0x4E
+	if m.DATA[0x30] != 0x4E {
+		t.Errorf("DATA[30] %02X != 4E", m.DATA[0x30])
+	}
+	if m.SFR[asm.ACC] != 0x51 {
+		t.Errorf("ACC %02X != 51", m.SFR[asm.ACC])
+	}
+}
@@ -0,0 +1,66 @@
+package asm
+
+// Peripheral device attached to machine, on-chip or on board,
+// embed BasePeripheral to implement only the needed methods
+type Peripheral interface {
+	// SFRs addresses of SFR handled by ReadSFR and WriteSFR
+	SFRs() []uint8
+	// ReadSFR read of handled SFR by instruction
+	ReadSFR(m *Machine, addr uint8) uint8
+	// WriteSFR write of handled SFR by instruction, replace the store of m.SFR
+	WriteSFR(m *Machine, addr uint8, val uint8)
+	// Tick run by machine cycles after each instruction, raise error by m.Fault
+	Tick(m *Machine, cycles uint64)
+	// Next machine cycles until next scheduled event which may request interrupt,
+	// 0: none scheduled, idle mode fast-forward to it
+	Next(m *Machine) uint64
+	// Reset to reset state, on attach and each machine reset
+	Reset(m *Machine)
+	// Interrupts interrupt sources of peripheral, polled after built-in sources
+	Interrupts() []Interrupt
+}
+
+// BasePeripheral no-op Peripheral, SFR stored in m.SFR
+type BasePeripheral struct{}
+
+// SFRs no SFR handled
+func (BasePeripheral) SFRs() []uint8 { return nil }
+
+// ReadSFR read m.SFR
+func (BasePeripheral) ReadSFR(m *Machine, addr uint8) uint8 { return m.SFR[addr] }
+
+// WriteSFR store to m.SFR
+func (BasePeripheral) WriteSFR(m *Machine, addr uint8, val uint8) { m.SFR[addr] = val }
+
+// Tick do nothing
+func (BasePeripheral) Tick(m *Machine, cycles uint64) {}
+
+// Next none scheduled
+func (BasePeripheral) Next(m *Machine) uint64 { return 0 }
+
+// Reset do nothing
+func (BasePeripheral) Reset(m *Machine) {}
+
+// Interrupts no interrupt source
+func (BasePeripheral) Interrupts() []Interrupt { return nil }
+
+// attached exported Peripheral run as built-in peripheral
+type attached struct {
+	p Peripheral
+}
+
+func (a attached) tick(m *Machine, cycles uint64) { a.p.Tick(m, cycles) }
+func (a attached) next(m *Machine) uint64         { return a.p.Next(m) }
+func (a attached) reset(m *Machine)               { a.p.Reset(m) }
+
+// Attach attach peripheral to machine, its SFR handlers override built-in ones
+func (m *Machine) Attach(p Peripheral) {
+	for _, addr := range p.SFRs() {
+		addr := addr
+		m.sfrRead[addr] = func(m *Machine) uint8 { return p.ReadSFR(m, addr) }
+		m.sfrWrite[addr] = func(m *Machine, val uint8) { p.WriteSFR(m, addr, val) }
+	}
+	m.interrupts = append(m.interrupts, p.Interrupts()...)
+	m.peripherals = append(m.peripherals, attached{p})
+	p.Reset(m)
+}
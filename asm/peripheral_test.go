@@ -0,0 +1,85 @@
+package asm_test
+
+import (
+	"testing"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+// countdown timer at SFR 0xE8, interrupt at 0x0033 when it reach 0
+type countdown struct {
+	asm.BasePeripheral
+	count   uint64
+	request bool
+	resets  int
+}
+
+func (c *countdown) SFRs() []uint8 { return []uint8{0xE8} }
+
+func (c *countdown) ReadSFR(m *asm.Machine, addr uint8) uint8 { return uint8(c.count) }
+
+func (c *countdown) WriteSFR(m *asm.Machine, addr uint8, val uint8) { c.count = uint64(val) }
+
+func (c *countdown) Tick(m *asm.Machine, cycles uint64) {
+	if c.count == 0 {
+		return
+	}
+	if cycles >= c.count {
+		c.count = 0
+		c.request = true
+		return
+	}
+	c.count -= cycles
+}
+
+func (c *countdown) Next(m *asm.Machine) uint64 { return c.count }
+
+func (c *countdown) Reset(m *asm.Machine) {
+	c.count = 0
+	c.request = false
+	c.resets++
+}
+
+func (c *countdown) Interrupts() []asm.Interrupt {
+	return []asm.Interrupt{{
+		Name: "Countdown", Vector: 0x0033,
+		Request: func(m *asm.Machine) bool { return c.request },
+		Ack:     func(m *asm.Machine) { c.request = false },
+	}}
+}
+
+func Test_Peripheral_Attach(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	c := &countdown{}
+	m.Attach(c)
+	if c.resets != 1 {
+		t.Errorf("reset on attach %d times", c.resets)
+	}
+	m.ROM = make([]byte, 0x40)
+	copy(m.ROM[0x00:], []byte{0x02, 0x00, 0x10}) // 0000: LJMP 0010
+	copy(m.ROM[0x10:], []byte{
+		0x75, 0xA8, 0x80, // 0010: MOV IE, #0x80, EA
+		0x75, 0xE8, 0x64, // 0013: MOV 0xE8, #100
+		0xE5, 0xE8, // 0016: MOV A, 0xE8
+		0x43, 0x87, 0x01, // 0018: ORL PCON, #0x01, idle
+		0x80, 0xFE, // 001B: SJMP 001B
+	})
+	copy(m.ROM[0x33:], []byte{
+		0x05, 0x40, // 0033: INC 0x40
+		0x32, // 0035: RETI
+	})
+	for i := 0; i < 8; i++ {
+		m.Single()
+	}
+	if m.SFR[asm.ACC] != 100-2 {
+		t.Errorf("read SFR by peripheral ACC %d", m.SFR[asm.ACC])
+	}
+	if m.DATA[0x40] != 1 || m.Cycles > 120 {
+		t.Errorf("interrupt %d times, at cycle %d", m.DATA[0x40], m.Cycles)
+	}
+
+	m.Reset()
+	if c.resets != 2 {
+		t.Errorf("reset with machine %d times", c.resets)
+	}
+}
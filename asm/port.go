@@ -0,0 +1,183 @@
+package asm
+
+import "errors"
+
+// ErrInvalidPin port not P0~P3 or bit not 0~7
+var ErrInvalidPin = errors.New("invalid port pin")
+
+// PinDrive external drive of port pin by host
+type PinDrive int
+
+const (
+	// DriveRelease pin not driven by host
+	DriveRelease PinDrive = iota
+	// DriveHigh pin driven high by host, a latch 0 still pulls it low
+	DriveHigh
+	// DriveLow pin driven low by host
+	DriveLow
+)
+
+// PinLevel level of port pin
+type PinLevel int
+
+const (
+	// PinLow pin is low
+	PinLow PinLevel = iota
+	// PinHigh pin is high
+	PinHigh
+	// PinFloat pin is high impedance, P0 open-drain output 1 without drive, read as 1
+	PinFloat
+)
+
+func (l PinLevel) String() string {
+	switch l {
+	case PinLow:
+		return "Low"
+	case PinHigh:
+		return "High"
+	}
+	return "Float"
+}
+
+// portAddr SFR address of P0~P3
+var portAddr = [4]uint8{P0, P1, P2, P3}
+
+// ports I/O port P0~P3,
+// P1~P3 quasi-bidirectional with internal pull-up, P0 open-drain
+type ports struct {
+	driven [4]uint8 // pins driven by host
+	level  [4]uint8 // level of driven pins
+	high   [4]uint8 // last pin level high
+	float  [4]uint8 // last pin level float
+	watch  []func(m *Machine, port uint8, bit uint, level PinLevel)
+}
+
+func newPorts() *ports {
+	return &ports{}
+}
+
+// portIndex P0~P3 address to index 0~3, -1 if not a port or bit not 0~7
+func portIndex(port uint8, bit uint) int {
+	if bit > 7 {
+		return -1
+	}
+	for k, addr := range portAddr {
+		if addr == port {
+			return k
+		}
+	}
+	return -1
+}
+
+// levels pin levels by latch and host drive, wired-AND
+func (p *ports) levels(m *Machine, i int) (high uint8, float uint8) {
+	latch := m.SFR[portAddr[i]]
+	drivenLow := p.driven[i] &^ p.level[i]
+	if i == 0 {
+		// open-drain, latch 1 is high impedance
+		high = latch & p.driven[i] & p.level[i]
+		float = latch &^ p.driven[i]
+		return high, float
+	}
+	return latch &^ drivenLow, 0
+}
+
+// update recalculate pin levels, notify pin level changes
+func (p *ports) update(m *Machine, i int) {
+	high, float := p.levels(m, i)
+	changed := (high ^ p.high[i]) | (float ^ p.float[i])
+	p.high[i] = high
+	p.float[i] = float
+	if changed == 0 {
+		return
+	}
+	for bit := uint(0); bit < 8; bit++ {
+		if changed&(1<<bit) == 0 {
+			continue
+		}
+		level := PinLow
+		if high&(1<<bit) != 0 {
+			level = PinHigh
+		} else if float&(1<<bit) != 0 {
+			level = PinFloat
+		}
+		for _, fn := range p.watch {
+			fn(m, portAddr[i], bit, level)
+		}
+	}
+}
+
+// busAccess external memory access by instruction, P0 is the multiplexed address/data bus,
+// its latch is written with 1s
+func (m *Machine) busAccess() {
+	if m.SFR[P0] != 0xFF {
+		m.SFR[P0] = 0xFF
+		m.ports.update(m, 0)
+	}
+}
+
+// read port pins, read-modify-write instructions read the latch instead
+func (p *ports) read(i int) func(m *Machine) uint8 {
+	return func(m *Machine) uint8 {
+		if m.rmw {
+			return m.SFR[portAddr[i]]
+		}
+		high, float := p.levels(m, i)
+		return high | float
+	}
+}
+
+// write port latch
+func (p *ports) write(i int) func(m *Machine, val uint8) {
+	return func(m *Machine, val uint8) {
+		m.SFR[portAddr[i]] = val
+		p.update(m, i)
+	}
+}
+
+// DrivePin drive port pin by host, port: P0~P3, bit: 0~7, ErrInvalidPin for other pins
+func (m *Machine) DrivePin(port uint8, bit uint, d PinDrive) error {
+	i := portIndex(port, bit)
+	if i < 0 {
+		return ErrInvalidPin
+	}
+	mask := uint8(1) << bit
+	switch d {
+	case DriveRelease:
+		m.ports.driven[i] &^= mask
+	case DriveHigh:
+		m.ports.driven[i] |= mask
+		m.ports.level[i] |= mask
+	case DriveLow:
+		m.ports.driven[i] |= mask
+		m.ports.level[i] &^= mask
+	}
+	m.ports.update(m, i)
+	return nil
+}
+
+// ReadPin level of port pin, port: P0~P3, bit: 0~7, other pins are not connected and float
+func (m *Machine) ReadPin(port uint8, bit uint) PinLevel {
+	i := portIndex(port, bit)
+	if i < 0 {
+		return PinFloat
+	}
+	high, float := m.ports.levels(m, i)
+	switch {
+	case high&(1<<bit) != 0:
+		return PinHigh
+	case float&(1<<bit) != 0:
+		return PinFloat
+	}
+	return PinLow
+}
+
+// WatchPins subscribe pin level changes of all ports
+func (m *Machine) WatchPins(fn func(m *Machine, port uint8, bit uint, level PinLevel)) {
+	m.ports.watch = append(m.ports.watch, fn)
+}
+
+// pin level of port pin read by on-chip peripherals, float read as high
+func (m *Machine) pin(port uint8, bit uint) bool {
+	return m.ReadPin(port, bit) != PinLow
+}
@@ -0,0 +1,111 @@
+package asm_test
+
+import (
+	"testing"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+func Test_Port_ReadModifyWrite(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x75, 0x90, 0xFF, // 0000: MOV P1, #0xFF
+		0xE5, 0x90, // 0003: MOV A, P1, read pins
+		0x53, 0x90, 0xF3, // 0005: ANL P1, #0xF3, read latch
+		0xA2, 0x90, // 0008: MOV C, P1.0, read pin
+	}
+	m.DrivePin(asm.P1, 0, asm.DriveLow)
+	for m.PC < uint(len(m.ROM)) {
+		m.Single()
+	}
+	if m.SFR[asm.ACC] != 0xFE {
+		t.Errorf("MOV A, P1 should read pins, A %02X", m.SFR[asm.ACC])
+	}
+	if m.SFR[asm.P1] != 0xF3 {
+		t.Errorf("ANL P1 should read latch, P1 %02X", m.SFR[asm.P1])
+	}
+	if m.Flag(asm.FlagCY) {
+		t.Errorf("MOV C, P1.0 should read pin low")
+	}
+
+	m.DrivePin(asm.P1, 0, asm.DriveRelease)
+	if m.ReadDATA(asm.P1) != 0xF3 {
+		t.Errorf("released pins follow latch, P1 %02X", m.ReadDATA(asm.P1))
+	}
+	m.DrivePin(asm.P1, 2, asm.DriveHigh)
+	if m.ReadPin(asm.P1, 2) != asm.PinLow {
+		t.Errorf("latch 0 pulls pin low against drive high")
+	}
+}
+
+func Test_Port_OpenDrain(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.WriteDATA(asm.P0, 0x0F)
+	if l := m.ReadPin(asm.P0, 0); l != asm.PinFloat {
+		t.Errorf("P0.0 latch 1 released %s", l)
+	}
+	if l := m.ReadPin(asm.P0, 7); l != asm.PinLow {
+		t.Errorf("P0.7 latch 0 %s", l)
+	}
+	m.DrivePin(asm.P0, 0, asm.DriveHigh)
+	m.DrivePin(asm.P0, 1, asm.DriveLow)
+	if m.ReadPin(asm.P0, 0) != asm.PinHigh || m.ReadPin(asm.P0, 1) != asm.PinLow {
+		t.Errorf("P0 driven %s %s", m.ReadPin(asm.P0, 0), m.ReadPin(asm.P0, 1))
+	}
+	if m.ReadDATA(asm.P0) != 0x0D {
+		t.Errorf("P0 read %02X", m.ReadDATA(asm.P0))
+	}
+}
+
+func Test_Port_Watch(t *testing.T) {
+	type change struct {
+		port  uint8
+		bit   uint
+		level asm.PinLevel
+	}
+	var changes []change
+
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.WatchPins(func(m *asm.Machine, port uint8, bit uint, level asm.PinLevel) {
+		changes = append(changes, change{port, bit, level})
+	})
+	m.ROM = []byte{
+		0xC2, 0x93, // 0000: CLR P1.3
+		0xC2, 0x93, // 0002: CLR P1.3
+		0xD2, 0x93, // 0004: SETB P1.3
+	}
+	for m.PC < uint(len(m.ROM)) {
+		m.Single()
+	}
+	m.DrivePin(asm.P3, 2, asm.DriveLow)
+
+	want := []change{
+		{asm.P1, 3, asm.PinLow},
+		{asm.P1, 3, asm.PinHigh},
+		{asm.P3, 2, asm.PinLow},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("changes %v", changes)
+	}
+	for k := range want {
+		if changes[k] != want[k] {
+			t.Errorf("change %d: %v != %v", k, changes[k], want[k])
+		}
+	}
+}
+
+func Test_Port_InvalidPin(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	if err := m.DrivePin(asm.ACC, 0, asm.DriveLow); err != asm.ErrInvalidPin {
+		t.Errorf("drive ACC.0 %v", err)
+	}
+	if err := m.DrivePin(asm.P1, 8, asm.DriveLow); err != asm.ErrInvalidPin {
+		t.Errorf("drive P1.8 %v", err)
+	}
+	if l := m.ReadPin(asm.ACC, 0); l != asm.PinFloat {
+		t.Errorf("read ACC.0 %s", l)
+	}
+	if err := m.DrivePin(asm.P1, 0, asm.DriveLow); err != nil || m.ReadPin(asm.P1, 0) != asm.PinLow {
+		t.Errorf("drive P1.0 %v", err)
+	}
+}
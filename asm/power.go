@@ -0,0 +1,63 @@
+package asm
+
+// PCON bits
+const (
+	pconIDL  uint8 = 1 << 0 // idle, CPU stopped, peripherals keep running
+	pconPD   uint8 = 1 << 1 // power-down, oscillator stopped
+	pconGF0  uint8 = 1 << 2 // general purpose flag
+	pconGF1  uint8 = 1 << 3 // general purpose flag
+	pconSMOD uint8 = 1 << 7 // double baud rate
+)
+
+// Idle machine in idle mode by PCON.IDL, terminated by any enabled interrupt
+func (m *Machine) Idle() bool {
+	return m.SFR[PCON]&pconIDL != 0
+}
+
+// PowerDown machine in power-down mode by PCON.PD,
+// terminated by enabled external interrupt as most derivatives do
+func (m *Machine) PowerDown() bool {
+	return m.SFR[PCON]&pconPD != 0
+}
+
+// sleep run one step of idle or power-down mode,
+// idle fast-forward peripherals to the next scheduled event instead of cycle stepping
+func (m *Machine) sleep() {
+	if m.PowerDown() {
+		// oscillator stopped, only external interrupt pins are sampled
+		m.extInt.tick(m, 0)
+	} else {
+		m.tick(m.nextEvent())
+	}
+	if c := m.pollInterrupt(); c != 0 {
+		m.tick(c)
+	}
+}
+
+// wakeup interrupt vectored, terminate idle and power-down mode
+func (m *Machine) wakeup() {
+	m.SFR[PCON] &^= pconIDL | pconPD
+}
+
+// nextEvent machine cycles until the earliest scheduled event of peripherals, at least 1
+func (m *Machine) nextEvent() uint64 {
+	var n uint64
+	for _, p := range m.peripherals {
+		n = minEvent(n, p.next(m))
+	}
+	if m.idleEnd > m.Cycles {
+		n = minEvent(n, m.idleEnd-m.Cycles)
+	}
+	if n == 0 {
+		return 1
+	}
+	return n
+}
+
+// minEvent earlier of two events, 0 is no event
+func minEvent(a, b uint64) uint64 {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
@@ -0,0 +1,120 @@
+package asm_test
+
+import (
+	"testing"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+func Test_Idle_FastForward(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = make([]byte, 0x50)
+	copy(m.ROM[0x00:], []byte{0x02, 0x00, 0x30}) // 0000: LJMP 0030
+	copy(m.ROM[0x0B:], []byte{
+		0x75, 0x8C, 0x3C, // 000B: MOV TH0, #0x3C
+		0x75, 0x8A, 0xB0, // 000E: MOV TL0, #0xB0
+		0x05, 0x40, // 0011: INC 0x40
+		0x32, // 0013: RETI
+	})
+	copy(m.ROM[0x30:], []byte{
+		0x75, 0x89, 0x01, // 0030: MOV TMOD, #0x01, timer 0 mode 1
+		0x75, 0x8C, 0x3C, // 0033: MOV TH0, #0x3C, 50000 cycles
+		0x75, 0x8A, 0xB0, // 0036: MOV TL0, #0xB0
+		0x75, 0xA8, 0x82, // 0039: MOV IE, #0x82, EA ET0
+		0xD2, 0x8C, // 003C: SETB TR0
+		0x43, 0x87, 0x01, // 003E: ORL PCON, #0x01, idle
+		0x05, 0x41, // 0041: INC 0x41
+		0x80, 0xF9, // 0043: SJMP 003E
+	})
+
+	steps := 0
+	for m.DATA[0x40] < 20 {
+		m.Single()
+		steps++
+	}
+	// interrupt every 50000 cycles, plus latency of reload
+	if m.Cycles < 1000000 || m.Cycles > 1000500 {
+		t.Errorf("20th timer 0 interrupt at cycle %d", m.Cycles)
+	}
+	if steps > 500 {
+		t.Errorf("idle not fast-forward, %d steps", steps)
+	}
+
+	// return to the instruction after ORL PCON, then idle again
+	for m.PC != 0x41 {
+		m.Single()
+	}
+	for i := 0; i < 3; i++ {
+		m.Single()
+	}
+	if !m.Idle() || m.DATA[0x41] != 20 {
+		t.Errorf("not in idle after wakeup, PC:%04X", m.PC)
+	}
+}
+
+func Test_PowerDown_Wakeup(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = make([]byte, 0x40)
+	copy(m.ROM[0x00:], []byte{0x02, 0x00, 0x30}) // 0000: LJMP 0030
+	copy(m.ROM[0x03:], []byte{
+		0x05, 0x40, // 0003: INC 0x40
+		0x32, // 0005: RETI
+	})
+	copy(m.ROM[0x30:], []byte{
+		0x75, 0xB0, 0xFF, // 0030: MOV P3, #0xFF
+		0x75, 0x88, 0x01, // 0033: MOV TCON, #0x01, IT0
+		0x75, 0xA8, 0x81, // 0036: MOV IE, #0x81, EA EX0
+		0x43, 0x87, 0x02, // 0039: ORL PCON, #0x02, power-down
+		0x80, 0xFE, // 003C: SJMP 003C
+	})
+
+	for i := 0; i < 10; i++ {
+		m.Single()
+	}
+	if !m.PowerDown() || m.PC != 0x3C {
+		t.Fatalf("not in power-down, PC:%04X", m.PC)
+	}
+	cycles := m.Cycles
+	for i := 0; i < 10; i++ {
+		m.Single()
+	}
+	if m.Cycles != cycles {
+		t.Errorf("clock run in power-down, %d cycles", m.Cycles-cycles)
+	}
+
+	m.DrivePin(asm.P3, 2, asm.DriveLow)
+	for i := 0; i < 3; i++ {
+		m.Single()
+	}
+	if m.PowerDown() || m.DATA[0x40] != 1 {
+		t.Errorf("not wakeup by INT0, PCON:%02X", m.SFR[asm.PCON])
+	}
+}
+
+func Test_PowerDown_Run(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = make([]byte, 0x40)
+	copy(m.ROM[0x00:], []byte{0x02, 0x00, 0x30}) // 0000: LJMP 0030
+	copy(m.ROM[0x03:], []byte{
+		0x05, 0x40, // 0003: INC 0x40
+		0x32, // 0005: RETI
+	})
+	copy(m.ROM[0x30:], []byte{
+		0x75, 0xA8, 0x81, // 0030: MOV IE, #0x81, EA EX0
+		0x75, 0x87, 0x02, // 0033: MOV PCON, #0x02, power-down
+		0x80, 0xFE, // 0036: SJMP 0036
+	})
+
+	h := m.RunCycles(100)
+	if h.Reason != asm.HaltPowerDown || h.PC != 0x36 {
+		t.Fatalf("power-down %s", h)
+	}
+	if h := m.RunCycles(100); h.Reason != asm.HaltPowerDown {
+		t.Errorf("power-down again %s", h)
+	}
+
+	m.DrivePin(asm.P3, 2, asm.DriveLow)
+	if h := m.RunCycles(100); h.Reason != asm.HaltLimit || m.DATA[0x40] == 0 {
+		t.Errorf("wakeup %s, INT0 %d", h, m.DATA[0x40])
+	}
+}
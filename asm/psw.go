@@ -0,0 +1,120 @@
+package asm
+
+// PSW : Program Status Word bits
+const (
+	// FlagP : parity of ACC, set by hardware
+	FlagP uint8 = 1 << 0
+	// FlagF1 : user flag 1
+	FlagF1 uint8 = 1 << 1
+	// FlagOV : overflow flag
+	FlagOV uint8 = 1 << 2
+	// FlagRS0 : register bank select bit 0
+	FlagRS0 uint8 = 1 << 3
+	// FlagRS1 : register bank select bit 1
+	FlagRS1 uint8 = 1 << 4
+	// FlagF0 : user flag 0
+	FlagF0 uint8 = 1 << 5
+	// FlagAC : auxiliary carry flag
+	FlagAC uint8 = 1 << 6
+	// FlagCY : carry flag
+	FlagCY uint8 = 1 << 7
+)
+
+// Flag read PSW flag bit
+func (m *Machine) Flag(mask uint8) bool {
+	return m.ReadDATA(PSW)&mask != 0
+}
+
+// SetFlag write PSW flag bits
+func (m *Machine) SetFlag(mask uint8, val bool) {
+	psw := m.ReadDATA(PSW)
+	if val {
+		psw |= mask
+	} else {
+		psw &^= mask
+	}
+	m.WriteDATA(PSW, psw)
+}
+
+// carry read PSW CY
+func (m *Machine) carry() bool {
+	return m.Flag(FlagCY)
+}
+
+// setCarry write PSW CY
+func (m *Machine) setCarry(c bool) {
+	m.SetFlag(FlagCY, c)
+}
+
+// auxCarry read PSW AC
+func (m *Machine) auxCarry() bool {
+	return m.Flag(FlagAC)
+}
+
+// parity ACC parity, true if number of 1 is odd
+func parity(val uint8) bool {
+	val ^= val >> 4
+	val ^= val >> 2
+	val ^= val >> 1
+	return val&0x01 != 0
+}
+
+// updateParity P flag always follow ACC, without triggering hooks
+func (m *Machine) updateParity() {
+	if parity(m.SFR[ACC]) {
+		m.SFR[PSW] |= FlagP
+	} else {
+		m.SFR[PSW] &^= FlagP
+	}
+}
+
+// add a + b + c, affect CY AC OV
+func (m *Machine) add(a, b uint8, c bool) uint8 {
+	var ci uint
+	if c {
+		ci = 1
+	}
+	r := uint(a) + uint(b) + ci
+	ac := (uint(a)&0x0F)+(uint(b)&0x0F)+ci > 0x0F
+	// carry into bit 7 without carry out of bit 7, or carry out without carry in
+	ov := (^(a ^ b) & (a ^ uint8(r)) & 0x80) != 0
+
+	psw := m.ReadDATA(PSW) &^ (FlagCY | FlagAC | FlagOV)
+	if r > 0xFF {
+		psw |= FlagCY
+	}
+	if ac {
+		psw |= FlagAC
+	}
+	if ov {
+		psw |= FlagOV
+	}
+	m.WriteDATA(PSW, psw)
+	return uint8(r)
+}
+
+// subb a - b - c, affect CY AC OV
+func (m *Machine) subb(a, b uint8, c bool) uint8 {
+	var ci uint8
+	if c {
+		ci = 1
+	}
+	r := a - b - ci
+	cy := uint(a) < uint(b)+uint(ci)
+	ac := a&0x0F < b&0x0F+ci
+	// borrow into bit 7 without borrow out of bit 7, or borrow out without borrow in
+	ov := ((a ^ b) & (a ^ r) & 0x80) != 0
+
+	psw := m.ReadDATA(PSW) &^ (FlagCY | FlagAC | FlagOV)
+	if cy {
+		psw |= FlagCY
+	}
+	if ac {
+		psw |= FlagAC
+	}
+	if ov {
+		psw |= FlagOV
+	}
+	m.WriteDATA(PSW, psw)
+	return r
+}
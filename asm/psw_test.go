@@ -0,0 +1,76 @@
+package asm_test
+
+import (
+	"testing"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+func Test_PSW_Flags(t *testing.T) {
+	tests := []struct {
+		name string
+		rom  []byte
+		a    uint8
+		b    uint8
+		cy   bool
+		ac   bool
+		ov   bool
+		resA uint8
+	}{
+		{"ADD carry", []byte{0x25, 0xF0}, 0xC3, 0xAA, true, false, true, 0x6D},
+		{"ADD aux carry", []byte{0x25, 0xF0}, 0x0F, 0x01, false, true, false, 0x10},
+		{"ADD overflow", []byte{0x25, 0xF0}, 0x7F, 0x01, false, true, true, 0x80},
+		{"SUBB borrow", []byte{0x95, 0xF0}, 0x49, 0x6A, true, true, false, 0xDF},
+		{"SUBB overflow", []byte{0x95, 0xF0}, 0x80, 0x01, false, true, true, 0x7F},
+		{"MUL overflow", []byte{0xA4}, 0x50, 0xA0, false, false, true, 0x00},
+		{"MUL", []byte{0xA4}, 0x05, 0x03, false, false, false, 0x0F},
+		{"DIV by zero", []byte{0x84}, 0x10, 0x00, false, false, true, 0x10},
+		{"DIV", []byte{0x84}, 0xFB, 0x12, false, false, false, 0x0D},
+	}
+
+	for _, tt := range tests {
+		m := asm.NewMachine(asm.Frequency1MHz)
+		m.ROM = tt.rom
+		m.WriteDATA(asm.ACC, tt.a)
+		m.WriteDATA(asm.B, tt.b)
+		m.Single()
+		if m.SFR[asm.ACC] != tt.resA {
+			t.Errorf("%s: A %02X != %02X", tt.name, m.SFR[asm.ACC], tt.resA)
+		}
+		if m.Flag(asm.FlagCY) != tt.cy || m.Flag(asm.FlagAC) != tt.ac || m.Flag(asm.FlagOV) != tt.ov {
+			t.Errorf("%s: PSW %02X", tt.name, m.SFR[asm.PSW])
+		}
+	}
+}
+
+func Test_PSW_DA(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x74, 0x56, // MOV A, #0x56
+		0x24, 0x67, // ADD A, #0x67
+		0xD4, // DA A
+	}
+	m.Single()
+	m.Single()
+	m.Single()
+	// BCD 56 + 67 = 123
+	if m.SFR[asm.ACC] != 0x23 || !m.Flag(asm.FlagCY) {
+		t.Errorf("DA A: %02X CY:%t", m.SFR[asm.ACC], m.Flag(asm.FlagCY))
+	}
+}
+
+func Test_PSW_Parity(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.WriteDATA(asm.ACC, 0x01)
+	if !m.Flag(asm.FlagP) {
+		t.Errorf("P should be set for %02X", m.SFR[asm.ACC])
+	}
+	m.WriteDATA(asm.PSW, 0x00)
+	if !m.Flag(asm.FlagP) {
+		t.Errorf("P is read only")
+	}
+	m.SFR[asm.ACC] = 0x03
+	if m.Flag(asm.FlagP) {
+		t.Errorf("P should be clear for %02X", m.SFR[asm.ACC])
+	}
+}
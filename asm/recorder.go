@@ -0,0 +1,304 @@
+package asm
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// TraceFormat output format of trace records
+type TraceFormat int
+
+const (
+	// TraceText one line of human readable text each record
+	TraceText TraceFormat = iota
+	// TraceJSONL JSON Lines, one JSON object each record
+	TraceJSONL
+	// TraceCSV CSV with header, registers and writes joined by space
+	TraceCSV
+)
+
+// RegChange register changed by instruction
+type RegChange struct {
+	Name string `json:"name"`
+	Old  uint16 `json:"old"`
+	New  uint16 `json:"new"`
+}
+
+func (r RegChange) String() string {
+	if r.Name == "DPTR" {
+		return fmt.Sprintf("%s:%04X>%04X", r.Name, r.Old, r.New)
+	}
+	return fmt.Sprintf("%s:%02X>%02X", r.Name, r.Old, r.New)
+}
+
+// MemWrite memory written by instruction
+type MemWrite struct {
+	Space Space `json:"space"`
+	Addr  uint  `json:"addr"`
+	Old   uint8 `json:"old"`
+	Value uint8 `json:"value"`
+}
+
+func (w MemWrite) String() string {
+	return fmt.Sprintf("%s:%04X=%02X", w.Space, w.Addr, w.Value)
+}
+
+// TraceRecord executed instruction record
+type TraceRecord struct {
+	Cycle  uint64      `json:"cycle"`  // machine cycles before the instruction
+	PC     uint        `json:"pc"`     // PC of the instruction
+	Code   string      `json:"code"`   // location in ROM, "bank:address" for banked code
+	Bytes  string      `json:"bytes"`  // opcode bytes in hex
+	Asm    string      `json:"asm"`    // disassembly
+	Cycles uint8       `json:"cycles"` // machine cycles of the instruction
+	Regs   []RegChange `json:"regs,omitempty"`
+	Writes []MemWrite  `json:"writes,omitempty"`
+}
+
+func (r TraceRecord) String() string {
+	s := fmt.Sprintf("%10d %s\t%-6s\t%-24s", r.Cycle, r.Code, r.Bytes, r.Asm)
+	for _, c := range r.Regs {
+		s += " " + c.String()
+	}
+	for _, w := range r.Writes {
+		s += " " + w.String()
+	}
+	return s
+}
+
+// traceCSVHeader CSV columns of TraceRecord
+var traceCSVHeader = []string{"cycle", "pc", "code", "bytes", "asm", "cycles", "regs", "writes"}
+
+func (r TraceRecord) csv() []string {
+	regs := make([]string, len(r.Regs))
+	for k, c := range r.Regs {
+		regs[k] = c.String()
+	}
+	writes := make([]string, len(r.Writes))
+	for k, w := range r.Writes {
+		writes[k] = w.String()
+	}
+	return []string{
+		strconv.FormatUint(r.Cycle, 10),
+		fmt.Sprintf("%04X", r.PC),
+		r.Code,
+		r.Bytes,
+		r.Asm,
+		strconv.Itoa(int(r.Cycles)),
+		strings.Join(regs, " "),
+		strings.Join(writes, " "),
+	}
+}
+
+// traceRegs registers compared before and after instruction
+var traceRegs = []string{"R0", "R1", "R2", "R3", "R4", "R5", "R6", "R7", "A", "B", "PSW", "SP", "DPTR"}
+
+// regSnapshot register values in traceRegs order, R0~R7 of register bank at rb, read without hooks
+func (m *Machine) regSnapshot(rb uint) (regs [13]uint16) {
+	for k := uint(0); k < 8; k++ {
+		regs[k] = uint16(m.DATA[rb+k])
+	}
+	regs[8] = uint16(m.SFR[ACC])
+	regs[9] = uint16(m.SFR[B])
+	regs[10] = uint16(m.SFR[PSW])
+	regs[11] = uint16(m.SFR[SP])
+	regs[12] = uint16(m.SFR[DPH])<<8 | uint16(m.SFR[DPL])
+	return
+}
+
+// addrRange ROM offset range, end included
+type addrRange struct {
+	start, end uint
+}
+
+// TraceRecorder record executed instructions in a ring buffer of the last N,
+// and stream them to writer, attach by Machine.Record
+type TraceRecorder struct {
+	// Filter record instruction only if true, nil always true
+	Filter func(m *Machine, r *TraceRecord) bool
+
+	ring   []TraceRecord
+	next   int // next slot in ring
+	full   bool
+	ranges []addrRange
+
+	w      io.Writer
+	format TraceFormat
+	csv    *csv.Writer
+	err    error // first stream error
+
+	// instruction recording
+	cur  TraceRecord
+	regs [13]uint16
+	rb   uint // register bank address before instruction
+	on   bool
+}
+
+// NewTraceRecorder recorder keep the last n records, 0 for streaming only
+func NewTraceRecorder(n int) *TraceRecorder {
+	return &TraceRecorder{ring: make([]TraceRecord, n)}
+}
+
+// AddRange record only instructions at ROM offset start~end, end included,
+// all instructions if no range added
+func (r *TraceRecorder) AddRange(start, end uint) {
+	r.ranges = append(r.ranges, addrRange{start, end})
+}
+
+// Stream write each record to w in format, nil to stop streaming
+func (r *TraceRecorder) Stream(w io.Writer, format TraceFormat) {
+	r.w, r.format, r.csv, r.err = w, format, nil, nil
+	if w != nil && format == TraceCSV {
+		r.csv = csv.NewWriter(w)
+		r.err = r.csv.Write(traceCSVHeader)
+		r.csv.Flush()
+	}
+}
+
+// Err first error of streaming
+func (r *TraceRecorder) Err() error {
+	return r.err
+}
+
+// Records records in ring buffer, oldest first
+func (r *TraceRecorder) Records() []TraceRecord {
+	if !r.full {
+		return append([]TraceRecord(nil), r.ring[:r.next]...)
+	}
+	return append(append([]TraceRecord(nil), r.ring[r.next:]...), r.ring[:r.next]...)
+}
+
+// Reset clear ring buffer
+func (r *TraceRecorder) Reset() {
+	r.next, r.full = 0, false
+}
+
+// Dump write records in ring buffer to w in format, oldest first
+func (r *TraceRecorder) Dump(w io.Writer, format TraceFormat) error {
+	var cw *csv.Writer
+	if format == TraceCSV {
+		cw = csv.NewWriter(w)
+		if err := cw.Write(traceCSVHeader); err != nil {
+			return err
+		}
+	}
+	for _, rec := range r.Records() {
+		if err := writeRecord(w, cw, format, rec); err != nil {
+			return err
+		}
+	}
+	if cw != nil {
+		cw.Flush()
+		return cw.Error()
+	}
+	return nil
+}
+
+// writeRecord write one record in format, cw for TraceCSV
+func writeRecord(w io.Writer, cw *csv.Writer, format TraceFormat, rec TraceRecord) error {
+	switch format {
+	case TraceJSONL:
+		b, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(append(b, '\n'))
+		return err
+	case TraceCSV:
+		return cw.Write(rec.csv())
+	}
+	_, err := fmt.Fprintln(w, rec)
+	return err
+}
+
+// inRange instruction at ROM offset in ranges
+func (r *TraceRecorder) inRange(code uint) bool {
+	if len(r.ranges) == 0 {
+		return true
+	}
+	for _, a := range r.ranges {
+		if code >= a.start && code <= a.end {
+			return true
+		}
+	}
+	return false
+}
+
+// begin start recording instruction at pc before executed
+func (r *TraceRecorder) begin(m *Machine, pc, code uint, ins *INS) {
+	r.on = r.inRange(code)
+	if !r.on {
+		return
+	}
+	const hex = "0123456789ABCDEF"
+	var buf [6]byte
+	n := 0
+	for i := uint(0); i < uint(ins.Bytes); i++ {
+		b := m.ReadCODE(pc + i)
+		buf[n], buf[n+1] = hex[b>>4], hex[b&0x0F]
+		n += 2
+	}
+	dis := ins.Mnemonic
+	if fake := ins.FakeCode(m, pc); fake != "" {
+		dis += " " + fake
+	}
+	r.cur = TraceRecord{
+		Cycle:  m.Cycles,
+		PC:     pc,
+		Code:   m.CodeString(code),
+		Bytes:  string(buf[:n]),
+		Asm:    dis,
+		Cycles: ins.Cycles,
+	}
+	r.rb = uint(m.GetBankSelect()) * 8
+	r.regs = m.regSnapshot(r.rb)
+}
+
+// write memory written by recording instruction
+func (r *TraceRecorder) write(space Space, addr uint, old, val uint8) {
+	if r.on {
+		r.cur.Writes = append(r.cur.Writes, MemWrite{Space: space, Addr: addr, Old: old, Value: val})
+	}
+}
+
+// end finish recording instruction after executed
+func (r *TraceRecorder) end(m *Machine) {
+	if !r.on {
+		return
+	}
+	r.on = false
+	regs := m.regSnapshot(r.rb)
+	for k := range regs {
+		if regs[k] != r.regs[k] {
+			r.cur.Regs = append(r.cur.Regs, RegChange{Name: traceRegs[k], Old: r.regs[k], New: regs[k]})
+		}
+	}
+	if r.Filter != nil && !r.Filter(m, &r.cur) {
+		return
+	}
+	if len(r.ring) > 0 {
+		r.ring[r.next] = r.cur
+		r.next++
+		if r.next == len(r.ring) {
+			r.next, r.full = 0, true
+		}
+	}
+	if r.w != nil && r.err == nil {
+		r.err = writeRecord(r.w, r.csv, r.format, r.cur)
+		if r.csv != nil {
+			r.csv.Flush()
+			if r.err == nil {
+				r.err = r.csv.Error()
+			}
+		}
+	}
+}
+
+// Record attach trace recorder, record each executed instruction, nil to detach
+func (m *Machine) Record(r *TraceRecorder) {
+	m.recorder = r
+}
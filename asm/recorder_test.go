@@ -0,0 +1,90 @@
+package asm_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+func Test_TraceRecorder(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x74, 0x05, // 0000: MOV A, #05H
+		0xF5, 0x30, // 0002: MOV 30H, A
+		0x90, 0x12, 0x34, // 0004: MOV DPTR, #1234H
+		0xF0,       // 0007: MOVX @DPTR, A
+		0x80, 0xF6, // 0008: SJMP 0000
+	}
+	r := asm.NewTraceRecorder(3)
+	var stream bytes.Buffer
+	r.Stream(&stream, asm.TraceJSONL)
+	m.Record(r)
+	m.RunInstructions(5)
+
+	recs := r.Records()
+	if len(recs) != 3 || recs[0].PC != 0x04 || recs[2].PC != 0x08 {
+		t.Fatalf("ring %v", recs)
+	}
+	movx := recs[1]
+	if movx.Bytes != "F0" || !strings.HasPrefix(movx.Asm, "MOVX") || movx.Cycles != 2 || movx.Cycle != 4 {
+		t.Errorf("record %+v", movx)
+	}
+	if len(movx.Writes) != 1 || movx.Writes[0] != (asm.MemWrite{Space: asm.SpaceXDATA, Addr: 0x1234, Value: 0x05}) {
+		t.Errorf("writes %v", movx.Writes)
+	}
+	if regs := recs[0].Regs; len(regs) != 1 || regs[0] != (asm.RegChange{Name: "DPTR", Old: 0, New: 0x1234}) {
+		t.Errorf("regs %v", regs)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stream.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("stream %d lines", len(lines))
+	}
+	var rec asm.TraceRecord
+	if err := json.Unmarshal([]byte(lines[1]), &rec); err != nil || rec.PC != 0x02 || rec.Writes[0].Addr != 0x30 {
+		t.Errorf("JSONL %s %v", lines[1], err)
+	}
+	if !strings.Contains(lines[1], `"space":"DATA"`) {
+		t.Errorf("JSONL space %s", lines[1])
+	}
+
+	var dump bytes.Buffer
+	if err := r.Dump(&dump, asm.TraceCSV); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&dump).ReadAll()
+	if err != nil || len(rows) != 4 || rows[0][0] != "cycle" || rows[2][7] != "XDATA:1234=05" {
+		t.Errorf("CSV %v %v", rows, err)
+	}
+}
+
+func Test_TraceRecorder_Filter(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x74, 0x05, // 0000: MOV A, #05H
+		0xF5, 0x30, // 0002: MOV 30H, A
+		0x90, 0x12, 0x34, // 0004: MOV DPTR, #1234H
+		0xF0,       // 0007: MOVX @DPTR, A
+		0x80, 0xF6, // 0008: SJMP 0000
+	}
+	r := asm.NewTraceRecorder(100)
+	r.AddRange(0x02, 0x07)
+	r.Filter = func(m *asm.Machine, rec *asm.TraceRecord) bool { return len(rec.Writes) > 0 }
+	m.Record(r)
+	m.RunInstructions(10)
+	recs := r.Records()
+	if len(recs) != 6 || recs[0].PC != 0x02 || recs[2].PC != 0x07 {
+		t.Errorf("filtered %v", recs)
+	}
+
+	m.Record(nil)
+	r.Reset()
+	m.RunInstructions(10)
+	if len(r.Records()) != 0 {
+		t.Errorf("detached")
+	}
+}
@@ -13,6 +13,8 @@ const (
 	SP  = 0x81
 	DPL = 0x82
 	DPH = 0x83
+	// PCON : power control
+	PCON = 0x87
 
 	// P0 : IO port 0
 	P0 = 0x80
@@ -24,23 +26,221 @@ const (
 	P3  = 0xB0
 	PSW = 0xD0
 
+	// TCON : timer control
+	TCON = 0x88
+	// TMOD : timer mode
+	TMOD = 0x89
+	// TL0 : timer 0 low byte
+	TL0 = 0x8A
+	// TL1 : timer 1 low byte
+	TL1 = 0x8B
+	// TH0 : timer 0 high byte
+	TH0 = 0x8C
+	// TH1 : timer 1 high byte
+	TH1 = 0x8D
+	// SCON : serial control
+	SCON = 0x98
+	// SBUF : serial buffer
+	SBUF = 0x99
+	// IE : interrupt enable
+	IE = 0xA8
+	// IP : interrupt priority
+	IP = 0xB8
+	// T2CON : timer 2 control, 8052
+	T2CON = 0xC8
+	// T2MOD : timer 2 mode, 8052
+	T2MOD = 0xC9
+	// RCAP2L : timer 2 capture/reload low byte, 8052
+	RCAP2L = 0xCA
+	// RCAP2H : timer 2 capture/reload high byte, 8052
+	RCAP2H = 0xCB
+	// TL2 : timer 2 low byte, 8052
+	TL2 = 0xCC
+	// TH2 : timer 2 high byte, 8052
+	TH2 = 0xCD
+
 	ACC = 0xE0
 	B   = 0xF0
 )
 
+// bit address
+const (
+	// P : PSW.0 parity flag
+	P = 0xD0
+	// OV : PSW.2 overflow flag
+	OV = 0xD2
+	// RS0 : PSW.3 register bank select bit 0
+	RS0 = 0xD3
+	// RS1 : PSW.4 register bank select bit 1
+	RS1 = 0xD4
+	// F0 : PSW.5 user flag 0
+	F0 = 0xD5
+	// AC : PSW.6 auxiliary carry flag
+	AC = 0xD6
+	// CY : PSW.7 carry flag
+	CY = 0xD7
+
+	// IT0 : TCON.0 INT0 edge triggered
+	IT0 = 0x88
+	// IE0 : TCON.1 INT0 request flag
+	IE0 = 0x89
+	// IT1 : TCON.2 INT1 edge triggered
+	IT1 = 0x8A
+	// IE1 : TCON.3 INT1 request flag
+	IE1 = 0x8B
+	// TR0 : TCON.4 timer 0 run
+	TR0 = 0x8C
+	// TF0 : TCON.5 timer 0 overflow flag
+	TF0 = 0x8D
+	// TR1 : TCON.6 timer 1 run
+	TR1 = 0x8E
+	// TF1 : TCON.7 timer 1 overflow flag
+	TF1 = 0x8F
+
+	// RI : SCON.0 receive interrupt flag
+	RI = 0x98
+	// TI : SCON.1 transmit interrupt flag
+	TI = 0x99
+	// RB8 : SCON.2 9th data bit received
+	RB8 = 0x9A
+	// TB8 : SCON.3 9th data bit to transmit
+	TB8 = 0x9B
+	// REN : SCON.4 receive enable
+	REN = 0x9C
+	// SM2 : SCON.5 multiprocessor communication enable
+	SM2 = 0x9D
+	// SM1 : SCON.6 serial mode bit 1
+	SM1 = 0x9E
+	// SM0 : SCON.7 serial mode bit 0
+	SM0 = 0x9F
+
+	// EX0 : IE.0 INT0 enable
+	EX0 = 0xA8
+	// ET0 : IE.1 timer 0 enable
+	ET0 = 0xA9
+	// EX1 : IE.2 INT1 enable
+	EX1 = 0xAA
+	// ET1 : IE.3 timer 1 enable
+	ET1 = 0xAB
+	// ES : IE.4 serial enable
+	ES = 0xAC
+	// ET2 : IE.5 timer 2 enable, 8052
+	ET2 = 0xAD
+	// EA : IE.7 global enable
+	EA = 0xAF
+
+	// PX0 : IP.0 INT0 priority
+	PX0 = 0xB8
+	// PT0 : IP.1 timer 0 priority
+	PT0 = 0xB9
+	// PX1 : IP.2 INT1 priority
+	PX1 = 0xBA
+	// PT1 : IP.3 timer 1 priority
+	PT1 = 0xBB
+	// PS : IP.4 serial priority
+	PS = 0xBC
+	// PT2 : IP.5 timer 2 priority, 8052
+	PT2 = 0xBD
+
+	// CPRL2 : T2CON.0 timer 2 capture/reload select, 8052
+	CPRL2 = 0xC8
+	// CT2 : T2CON.1 timer 2 counter/timer select, 8052
+	CT2 = 0xC9
+	// TR2 : T2CON.2 timer 2 run, 8052
+	TR2 = 0xCA
+	// EXEN2 : T2CON.3 timer 2 external enable, 8052
+	EXEN2 = 0xCB
+	// TCLK : T2CON.4 timer 2 as serial transmit clock, 8052
+	TCLK = 0xCC
+	// RCLK : T2CON.5 timer 2 as serial receive clock, 8052
+	RCLK = 0xCD
+	// EXF2 : T2CON.6 timer 2 external flag, 8052
+	EXF2 = 0xCE
+	// TF2 : T2CON.7 timer 2 overflow flag, 8052
+	TF2 = 0xCF
+)
+
+var bitList = []Register{
+	{0xD0, "P"},
+	{0xD2, "OV"},
+	{0xD3, "RS0"},
+	{0xD4, "RS1"},
+	{0xD5, "F0"},
+	{0xD6, "AC"},
+	{0xD7, "CY"},
+	{0x88, "IT0"},
+	{0x89, "IE0"},
+	{0x8A, "IT1"},
+	{0x8B, "IE1"},
+	{0x8C, "TR0"},
+	{0x8D, "TF0"},
+	{0x8E, "TR1"},
+	{0x8F, "TF1"},
+	{0x98, "RI"},
+	{0x99, "TI"},
+	{0x9A, "RB8"},
+	{0x9B, "TB8"},
+	{0x9C, "REN"},
+	{0x9D, "SM2"},
+	{0x9E, "SM1"},
+	{0x9F, "SM0"},
+	{0xA8, "EX0"},
+	{0xA9, "ET0"},
+	{0xAA, "EX1"},
+	{0xAB, "ET1"},
+	{0xAC, "ES"},
+	{0xAD, "ET2"},
+	{0xAF, "EA"},
+	{0xB8, "PX0"},
+	{0xB9, "PT0"},
+	{0xBA, "PX1"},
+	{0xBB, "PT1"},
+	{0xBC, "PS"},
+	{0xBD, "PT2"},
+	{0xC8, "CP/RL2"},
+	{0xC9, "C/T2"},
+	{0xCA, "TR2"},
+	{0xCB, "EXEN2"},
+	{0xCC, "TCLK"},
+	{0xCD, "RCLK"},
+	{0xCE, "EXF2"},
+	{0xCF, "TF2"},
+}
+
 var regList = []Register{
 	{0x81, "SP"},
 	{0x82, "DPL"},
 	{0x83, "DPH"},
+	{0x87, "PCON"},
 	{0x80, "P0"},
 	{0x90, "P1"},
 	{0xA0, "P2"},
 	{0xB0, "P3"},
 	{0xD0, "PSW"},
+	{0x88, "TCON"},
+	{0x89, "TMOD"},
+	{0x8A, "TL0"},
+	{0x8B, "TL1"},
+	{0x8C, "TH0"},
+	{0x8D, "TH1"},
+	{0x98, "SCON"},
+	{0x99, "SBUF"},
+	{0xA8, "IE"},
+	{0xB8, "IP"},
 	{0xE0, "ACC"},
 	{0xF0, "B"},
 }
 
+// regList8052 8052 extra registers
+var regList8052 = []Register{
+	{0xC8, "T2CON"},
+	{0xC9, "T2MOD"},
+	{0xCA, "RCAP2L"},
+	{0xCB, "RCAP2H"},
+	{0xCC, "TL2"},
+	{0xCD, "TH2"},
+}
+
 // FindRegByName find first one register by name, if not found return nil
 func FindRegByName(name string, list []Register) *Register {
 	for k, r := range list {
@@ -0,0 +1,74 @@
+package asm
+
+// ResetCause source of last reset
+type ResetCause int
+
+const (
+	// ResetPowerOn power-on reset
+	ResetPowerOn ResetCause = iota
+	// ResetExternal external RST pin held high
+	ResetExternal
+	// ResetWatchdog watchdog timer overflow
+	ResetWatchdog
+	// ResetSoftware software reset on derivatives
+	ResetSoftware
+)
+
+func (c ResetCause) String() string {
+	switch c {
+	case ResetPowerOn:
+		return "PowerOn"
+	case ResetExternal:
+		return "External"
+	case ResetWatchdog:
+		return "Watchdog"
+	}
+	return "Software"
+}
+
+// sfrReset SFR reset values which are not zero
+var sfrReset = map[uint8]uint8{
+	SP: 0x07,
+	P0: 0xFF,
+	P1: 0xFF,
+	P2: 0xFF,
+	P3: 0xFF,
+}
+
+// Reset reset machine by external RST pin
+func (m *Machine) Reset() {
+	m.ResetBy(ResetExternal)
+}
+
+// ResetBy reset machine by cause, PC and SFR to reset state,
+// reset flags of variant record the cause,
+// internal RAM, XDATA and machine cycles are kept
+func (m *Machine) ResetBy(cause ResetCause) {
+	old := m.SFR
+	m.PC = 0
+	for addr := 0x80; addr < 0x100; addr++ {
+		m.SFR[addr] = 0
+	}
+	for addr, val := range m.sfrReset {
+		m.SFR[addr] = val
+	}
+	for _, f := range m.Variant.ResetFlags {
+		switch {
+		case f.Cause == cause:
+			m.SFR[f.Addr] |= f.Mask
+		case f.Sticky:
+			m.SFR[f.Addr] |= old[f.Addr] & f.Mask
+		}
+	}
+	m.isrActive = 0
+	m.isrHold = false
+	m.rmw = false
+	m.LastReset = cause
+	m.resets++
+	for _, p := range m.peripherals {
+		p.reset(m)
+	}
+	for k := range portAddr {
+		m.ports.update(m, k)
+	}
+}
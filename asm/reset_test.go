@@ -0,0 +1,77 @@
+package asm_test
+
+import (
+	"testing"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+func Test_Reset(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	if m.LastReset != asm.ResetPowerOn || m.SFR[asm.SP] != 0x07 || m.SFR[asm.P3] != 0xFF {
+		t.Fatalf("power-on SP %02X P3 %02X cause %s", m.SFR[asm.SP], m.SFR[asm.P3], m.LastReset)
+	}
+
+	m.ROM = make([]byte, 0x40)
+	copy(m.ROM[0x00:], []byte{
+		0x75, 0x81, 0x30, // 0000: MOV SP, #0x30
+		0x75, 0x90, 0x00, // 0003: MOV P1, #0x00
+		0x74, 0x55, // 0006: MOV A, #0x55
+		0xF5, 0x40, // 0008: MOV 0x40, A
+		0x75, 0xA8, 0x82, // 000A: MOV IE, #0x82, EA ET0
+		0xD2, 0x8D, // 000D: SETB TF0
+		0x00,       // 000F: NOP
+		0x80, 0xFE, // 0010: SJMP 0010
+	})
+	for m.InterruptLevel() < 0 {
+		m.Single()
+	}
+
+	m.ResetBy(asm.ResetWatchdog)
+	if m.PC != 0 || m.SFR[asm.SP] != 0x07 || m.SFR[asm.P1] != 0xFF || m.SFR[asm.ACC] != 0 || m.SFR[asm.IE] != 0 {
+		t.Errorf("PC %04X SP %02X P1 %02X ACC %02X IE %02X", m.PC, m.SFR[asm.SP], m.SFR[asm.P1], m.SFR[asm.ACC], m.SFR[asm.IE])
+	}
+	if m.InterruptLevel() != -1 {
+		t.Errorf("interrupt in progress after reset")
+	}
+	if m.ReadPin(asm.P1, 0) != asm.PinHigh {
+		t.Errorf("P1.0 %s after reset", m.ReadPin(asm.P1, 0))
+	}
+	// internal RAM kept
+	if m.DATA[0x40] != 0x55 {
+		t.Errorf("DATA[40] %02X", m.DATA[0x40])
+	}
+	if m.LastReset != asm.ResetWatchdog {
+		t.Errorf("reset cause %s", m.LastReset)
+	}
+}
+
+func Test_Reset_FromTrace(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x05, 0x30, // 0000: INC 0x30
+		0x75, 0x31, 0xAA, // 0002: MOV 0x31, #0xAA
+		0x80, 0xF9, // 0005: SJMP 0000
+	}
+	resets := 0
+	m.Trace(0x02, func(m *asm.Machine) {
+		if resets == 0 {
+			resets++
+			m.Reset()
+		}
+	})
+
+	for i := 0; i < 3; i++ {
+		if err := m.Single(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// instruction at 0002 aborted by reset, INC executed again
+	if m.PC != 0x02 || m.DATA[0x30] != 2 || m.DATA[0x31] != 0 || m.LastReset != asm.ResetExternal {
+		t.Errorf("PC %04X 0x30 %d 0x31 %02X", m.PC, m.DATA[0x30], m.DATA[0x31])
+	}
+	m.Single()
+	if m.PC != 0x05 || m.DATA[0x31] != 0xAA {
+		t.Errorf("after reset PC %04X 0x31 %02X", m.PC, m.DATA[0x31])
+	}
+}
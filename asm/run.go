@@ -0,0 +1,175 @@
+package asm
+
+import (
+	"errors"
+	"fmt"
+)
+
+// HaltReason reason of run halt
+type HaltReason int
+
+const (
+	// HaltCondition run condition met
+	HaltCondition HaltReason = iota
+	// HaltBreakpoint Break called by breakpoint
+	HaltBreakpoint
+	// HaltIllegal illegal opcode
+	HaltIllegal
+	// HaltLimit cycles or instructions limit hit
+	HaltLimit
+	// HaltError execution error other than illegal opcode
+	HaltError
+	// HaltWatchpoint watchpoint with Halt hit, after the accessing instruction
+	HaltWatchpoint
+	// HaltPowerDown CPU in power-down mode and not woken up by external interrupt pins,
+	// machine cycles stopped, drive pins to wake it up before run again
+	HaltPowerDown
+)
+
+func (r HaltReason) String() string {
+	switch r {
+	case HaltCondition:
+		return "Condition"
+	case HaltBreakpoint:
+		return "Breakpoint"
+	case HaltIllegal:
+		return "Illegal"
+	case HaltLimit:
+		return "Limit"
+	case HaltWatchpoint:
+		return "Watchpoint"
+	case HaltPowerDown:
+		return "PowerDown"
+	}
+	return "Error"
+}
+
+// Halt run halt result
+type Halt struct {
+	Reason HaltReason
+	PC     uint      // PC at halt
+	Bank   uint      // selected code bank at halt, 0 without banking
+	Code   uint      // ROM offset of PC in selected bank
+	Err    error     // *ExecError of HaltIllegal and HaltError
+	Watch  *WatchHit // hit of HaltWatchpoint
+
+	loc string // PC as "bank:address" in bank window
+}
+
+func (h Halt) String() string {
+	loc := h.loc
+	if loc == "" {
+		loc = fmt.Sprintf("%04X", h.PC)
+	}
+	if h.Err != nil {
+		return fmt.Sprintf("%s at %s: %s", h.Reason, loc, h.Err)
+	}
+	if h.Watch != nil {
+		return fmt.Sprintf("%s at %s: %s %s %04X by %04X",
+			h.Reason, loc, h.Watch.Space, h.Watch.Kind, h.Watch.Addr, h.Watch.PC)
+	}
+	return fmt.Sprintf("%s at %s", h.Reason, loc)
+}
+
+// haltAt halt at pc in selected bank
+func (m *Machine) haltAt(r HaltReason, pc uint) Halt {
+	bank := m.Bank()
+	code := m.CodeAddr(bank, pc)
+	return Halt{Reason: r, PC: pc, Bank: bank, Code: code, loc: m.CodeString(code)}
+}
+
+// Break halt before the instruction at PC, called by Trace callback,
+// run and Start pause there, resume from there without calling the breakpoint again
+func (m *Machine) Break() {
+	m.breakReq = true
+}
+
+// RunCycles run n machine cycles as fast as possible, 0 return at once
+func (m *Machine) RunCycles(n uint64) Halt {
+	end := m.Cycles + n
+	if end < m.Cycles {
+		end = noLimit
+	}
+	return m.run(end, noLimit, nil)
+}
+
+// RunInstructions run n instructions as fast as possible,
+// each sleep step in idle or power-down mode count as one, 0 return at once
+func (m *Machine) RunInstructions(n uint64) Halt {
+	return m.run(noLimit, n, nil)
+}
+
+// RunUntil run until cond is true after an instruction
+func (m *Machine) RunUntil(cond func(m *Machine) bool) Halt {
+	return m.run(noLimit, noLimit, cond)
+}
+
+// RunUntilPC run until PC reach addr after an instruction, in any code bank
+func (m *Machine) RunUntilPC(addr uint) Halt {
+	return m.run(noLimit, noLimit, func(m *Machine) bool { return m.PC == addr })
+}
+
+// RunUntilBankPC run until PC reach bank:address after an instruction,
+// bank is ignored in common area
+func (m *Machine) RunUntilBankPC(bank uint, addr uint) Halt {
+	code := m.CodeAddr(bank, addr)
+	return m.run(noLimit, noLimit, func(m *Machine) bool { return m.atCode(code) })
+}
+
+// atCode PC at ROM offset in selected bank
+func (m *Machine) atCode(code uint) bool {
+	return m.CodeAddr(m.Bank(), m.PC) == code
+}
+
+// haltOf halt of step error
+func (m *Machine) haltOf(err error) Halt {
+	r := HaltError
+	if errors.Is(err, ErrIllegalOpcode) {
+		r = HaltIllegal
+	}
+	pc := m.PC
+	var e *ExecError
+	if errors.As(err, &e) {
+		pc = e.PC
+	}
+	h := m.haltAt(r, pc)
+	h.Err = err
+	return h
+}
+
+// noLimit endCycles or instruction count of run without limit
+const noLimit = ^uint64(0)
+
+// run step until cycles reach endCycles, n instructions or cond,
+// noLimit or nil for no limit, limit already reached return before any step
+func (m *Machine) run(endCycles uint64, n uint64, cond func(m *Machine) bool) Halt {
+	defer func() { m.idleEnd = 0 }()
+	m.idleEnd = endCycles
+	for i := uint64(0); ; i++ {
+		if m.Cycles >= endCycles || i >= n {
+			return m.haltAt(HaltLimit, m.PC)
+		}
+		pd := m.PowerDown()
+		if err := m.step(); err != nil {
+			m.watchHit = nil
+			return m.haltOf(err)
+		}
+		if hit := m.takeWatchHit(); hit != nil {
+			m.breakReq = false
+			h := m.haltAt(HaltWatchpoint, m.PC)
+			h.Watch = hit
+			return h
+		}
+		if m.breakReq {
+			m.breakReq = false
+			return m.haltAt(HaltBreakpoint, m.PC)
+		}
+		if cond != nil && cond(m) {
+			return m.haltAt(HaltCondition, m.PC)
+		}
+		if pd && m.PowerDown() {
+			// pins sampled once, nothing else can change while running
+			return m.haltAt(HaltPowerDown, m.PC)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package asm_test
+
+import (
+	"testing"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+func Test_Run(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x7F, 0x00, // 0000: MOV R7, #0x00
+		0x0F,       // 0002: INC R7
+		0x00,       // 0003: NOP
+		0x80, 0xFC, // 0004: SJMP 0002
+		0xA5, // 0006: reserved opcode
+	}
+
+	h := m.RunInstructions(4)
+	if h.Reason != asm.HaltLimit || m.PC != 0x02 {
+		t.Errorf("RunInstructions %s", h)
+	}
+
+	h = m.RunCycles(1000)
+	if h.Reason != asm.HaltLimit || m.Cycles < 1005 || m.Cycles > 1006 {
+		t.Errorf("RunCycles %s, cycles %d", h, m.Cycles)
+	}
+
+	h = m.RunUntil(func(m *asm.Machine) bool { return m.DATA[asm.R7] == 0xFF })
+	if h.Reason != asm.HaltCondition || m.PC != 0x03 {
+		t.Errorf("RunUntil %s", h)
+	}
+
+	h = m.RunUntilPC(0x04)
+	if h.Reason != asm.HaltCondition || h.PC != 0x04 {
+		t.Errorf("RunUntilPC %s", h)
+	}
+
+	m.PC = 0x06
+	h = m.RunCycles(10)
+	if h.Reason != asm.HaltIllegal || h.Err == nil || h.PC != 0x06 {
+		t.Errorf("illegal opcode %s", h)
+	}
+}
+
+func Test_Run_ZeroLimit(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x80, 0xFE, // 0000: SJMP $
+	}
+	if h := m.RunInstructions(0); h.Reason != asm.HaltLimit || m.Cycles != 0 {
+		t.Errorf("RunInstructions(0) %s, cycles %d", h, m.Cycles)
+	}
+	if h := m.RunCycles(0); h.Reason != asm.HaltLimit || m.Cycles != 0 {
+		t.Errorf("RunCycles(0) %s, cycles %d", h, m.Cycles)
+	}
+}
+
+func Test_Run_Break(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x0F,       // 0000: INC R7
+		0x80, 0xFD, // 0001: SJMP 0000
+	}
+	m.Trace(0x01, func(m *asm.Machine) {
+		m.Break()
+	})
+	for i := 0; i < 3; i++ {
+		h := m.RunCycles(1000)
+		// halt before SJMP, resume without break again
+		if h.Reason != asm.HaltBreakpoint || h.PC != 0x01 || m.DATA[asm.R7] != uint8(i+1) {
+			t.Errorf("break %d: %s, R7 %d", i, h, m.DATA[asm.R7])
+		}
+	}
+}
+
+func Test_Run_Idle(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x43, 0x87, 0x01, // 0000: ORL PCON, #0x01, idle
+	}
+	m.RunInstructions(1)
+	cycles := m.Cycles
+	h := m.RunCycles(100000)
+	if h.Reason != asm.HaltLimit || m.Cycles != cycles+100000 {
+		t.Errorf("idle %s, cycles %d", h, m.Cycles-cycles)
+	}
+}
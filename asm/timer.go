@@ -0,0 +1,205 @@
+package asm
+
+// TMOD bits, low nibble for timer 0, high nibble for timer 1
+const (
+	tmodM0   uint8 = 1 << 0
+	tmodM1   uint8 = 1 << 1
+	tmodCT   uint8 = 1 << 2 // counter of Tx pin falling edge
+	tmodGATE uint8 = 1 << 3 // run only when INTx pin is high
+)
+
+// timer01 timer/counter 0 and 1
+type timer01 struct {
+	pinT      [2]bool // last sampled level of T0 T1 pin
+	overflow1 uint64  // timer 1 overflow count, clock of serial port
+}
+
+func newTimer01() *timer01 {
+	return &timer01{}
+}
+
+// input count pulses of timer x in this machine cycles
+func (t *timer01) input(m *Machine, x uint, run bool, cycles uint64) uint64 {
+	ctrl := m.SFR[TMOD] >> (4 * x)
+
+	// T0: P3.4, T1: P3.5
+	level := m.pin(P3, 4+x)
+	fall := t.pinT[x] && !level
+	t.pinT[x] = level
+
+	// INT0: P3.2, INT1: P3.3
+	if !run || (ctrl&tmodGATE != 0 && !m.pin(P3, 2+x)) {
+		return 0
+	}
+	if ctrl&tmodCT != 0 {
+		if fall {
+			return 1
+		}
+		return 0
+	}
+	return timerPulses(m, cycles)
+}
+
+func (t *timer01) tick(m *Machine, cycles uint64) {
+	mode0 := m.SFR[TMOD] & (tmodM0 | tmodM1)
+	mode1 := (m.SFR[TMOD] >> 4) & (tmodM0 | tmodM1)
+
+	n0 := t.input(m, 0, m.bit(TR0), cycles)
+	if mode0 == 3 {
+		// split timer 0, TL0 controlled by timer 0 bits, TH0 controlled by TR1
+		if count8(m, TL0, n0) > 0 {
+			m.setBit(TF0, true)
+		}
+		if m.bit(TR1) && count8(m, TH0, timerPulses(m, cycles)) > 0 {
+			m.setBit(TF1, true)
+		}
+		// timer 1 still runs without TR1 and TF1, as baud rate generator
+		if mode1 != 3 {
+			t.overflow1 += timerCount(m, mode1, TL1, TH1, t.input(m, 1, true, cycles))
+		}
+		return
+	}
+	if timerCount(m, mode0, TL0, TH0, n0) > 0 {
+		m.setBit(TF0, true)
+	}
+
+	// timer 1 in mode 3 is stopped
+	n1 := t.input(m, 1, m.bit(TR1), cycles)
+	if mode1 != 3 {
+		if ovf := timerCount(m, mode1, TL1, TH1, n1); ovf > 0 {
+			t.overflow1 += ovf
+			m.setBit(TF1, true)
+		}
+	}
+}
+
+// count8 8-bit free running counter, return overflow count
+func count8(m *Machine, addr uint8, n uint64) uint64 {
+	val := uint64(m.SFR[addr]) + n
+	m.SFR[addr] = uint8(val)
+	return val >> 8
+}
+
+// timerCount count n pulses in mode, return overflow count
+//
+//	mode 0: 13-bit, TH 8-bit + TL 5-bit prescaler
+//	mode 1: 16-bit
+//	mode 2: TL 8-bit auto-reload from TH
+func timerCount(m *Machine, mode uint8, tl, th uint8, n uint64) uint64 {
+	var (
+		val, max, reload uint64
+	)
+	if n == 0 {
+		return 0
+	}
+	switch mode {
+	case 0:
+		val = uint64(m.SFR[th])<<5 | uint64(m.SFR[tl]&0x1F)
+		max = 1 << 13
+	case 1:
+		val = uint64(m.SFR[th])<<8 | uint64(m.SFR[tl])
+		max = 1 << 16
+	case 2:
+		val = uint64(m.SFR[tl])
+		max = 1 << 8
+		reload = uint64(m.SFR[th])
+	default:
+		return 0
+	}
+
+	var ovf uint64
+	val += n
+	if val >= max {
+		// first overflow from max, then count from reload value every period
+		period := max - reload
+		ovf = 1 + (val-max)/period
+		val = reload + (val-max)%period
+	}
+
+	switch mode {
+	case 0:
+		m.SFR[th] = uint8(val >> 5)
+		m.SFR[tl] = m.SFR[tl]&0xE0 | uint8(val&0x1F)
+	case 1:
+		m.SFR[th] = uint8(val >> 8)
+		m.SFR[tl] = uint8(val)
+	case 2:
+		m.SFR[tl] = uint8(val)
+	}
+	return ovf
+}
+
+// next machine cycles until overflow of timers with interrupt enabled
+func (t *timer01) next(m *Machine) uint64 {
+	if !m.bit(EA) {
+		return 0
+	}
+	mode0 := m.SFR[TMOD] & (tmodM0 | tmodM1)
+	mode1 := (m.SFR[TMOD] >> 4) & (tmodM0 | tmodM1)
+
+	var n uint64
+	if mode0 == 3 {
+		if m.bit(ET0) && t.timing(m, 0, m.bit(TR0)) {
+			n = minEvent(n, timerCycles(m, 0x100-uint64(m.SFR[TL0])))
+		}
+		if m.bit(ET1) && m.bit(TR1) {
+			n = minEvent(n, timerCycles(m, 0x100-uint64(m.SFR[TH0])))
+		}
+		return n
+	}
+	if m.bit(ET0) && t.timing(m, 0, m.bit(TR0)) {
+		n = minEvent(n, timerCycles(m, timerLeft(m, mode0, TL0, TH0)))
+	}
+	if m.bit(ET1) && t.timing(m, 1, m.bit(TR1)) {
+		n = minEvent(n, timerCycles(m, timerLeft(m, mode1, TL1, TH1)))
+	}
+	return n
+}
+
+// timing timer x is running and counting machine cycles
+func (t *timer01) timing(m *Machine, x uint, run bool) bool {
+	ctrl := m.SFR[TMOD] >> (4 * x)
+	if !run || ctrl&tmodCT != 0 {
+		return false
+	}
+	return ctrl&tmodGATE == 0 || m.pin(P3, 2+x)
+}
+
+// timerLeft pulses until overflow in mode, 0 when stopped
+func timerLeft(m *Machine, mode uint8, tl, th uint8) uint64 {
+	switch mode {
+	case 0:
+		return 1<<13 - (uint64(m.SFR[th])<<5 | uint64(m.SFR[tl]&0x1F))
+	case 1:
+		return 1<<16 - (uint64(m.SFR[th])<<8 | uint64(m.SFR[tl]))
+	case 2:
+		return 1<<8 - uint64(m.SFR[tl])
+	}
+	return 0
+}
+
+func (t *timer01) reset(m *Machine) {
+	t.pinT = [2]bool{true, true}
+}
+
+// timerPulses timer count pulses in this machine cycles, which already added to m.Cycles,
+// by clocks per timer count of variant
+func timerPulses(m *Machine, cycles uint64) uint64 {
+	cpc := uint64(m.ClocksPerCycle)
+	tc := uint64(m.Variant.TimerClocks)
+	if tc == 0 || tc == cpc {
+		return cycles
+	}
+	return m.Cycles*cpc/tc - (m.Cycles-cycles)*cpc/tc
+}
+
+// timerCycles machine cycles from now until n timer count pulses, 0 when n is 0
+func timerCycles(m *Machine, n uint64) uint64 {
+	cpc := uint64(m.ClocksPerCycle)
+	tc := uint64(m.Variant.TimerClocks)
+	if n == 0 || tc == 0 || tc == cpc {
+		return n
+	}
+	target := (m.Cycles*cpc/tc + n) * tc
+	return (target+cpc-1)/cpc - m.Cycles
+}
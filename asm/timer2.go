@@ -0,0 +1,141 @@
+package asm
+
+// T2MOD bits
+const (
+	t2modDCEN uint8 = 1 << 0 // up/down count enable by T2EX pin
+)
+
+// timer2 8052 timer/counter 2
+type timer2 struct {
+	pinT2    bool   // last sampled level of T2 pin
+	pinT2EX  bool   // last sampled level of T2EX pin
+	overflow uint64 // overflow count in baud rate generator mode, clock of serial port
+}
+
+func newTimer2() *timer2 {
+	return &timer2{}
+}
+
+func (t *timer2) tick(m *Machine, cycles uint64) {
+	// T2: P1.0, T2EX: P1.1
+	level := m.pin(P1, 0)
+	fall := t.pinT2 && !level
+	t.pinT2 = level
+	levelEX := m.pin(P1, 1)
+	fallEX := t.pinT2EX && !levelEX
+	t.pinT2EX = levelEX
+
+	baud := m.bit(RCLK) || m.bit(TCLK)
+
+	var n uint64
+	if m.bit(TR2) {
+		switch {
+		case m.bit(CT2):
+			if fall {
+				n = 1
+			}
+		case baud:
+			// baud rate generator increments every state, fosc/2,
+			// by total clocks to carry the odd clock of 1T core
+			cpc := uint64(m.ClocksPerCycle)
+			n = m.Cycles*cpc/2 - (m.Cycles-cycles)*cpc/2
+		default:
+			n = timerPulses(m, cycles)
+		}
+	}
+
+	val := uint64(m.SFR[TH2])<<8 | uint64(m.SFR[TL2])
+	rcap := uint64(m.SFR[RCAP2H])<<8 | uint64(m.SFR[RCAP2L])
+	exen := m.bit(EXEN2)
+
+	switch {
+	case baud:
+		// overflow reload from RCAP2, TF2 not set
+		var ovf uint64
+		val, ovf = count16(val, rcap, n)
+		t.overflow += ovf
+		if exen && fallEX {
+			m.setBit(EXF2, true)
+		}
+	case m.bit(CPRL2):
+		// capture on T2EX falling edge
+		var ovf uint64
+		val, ovf = count16(val, 0, n)
+		if ovf > 0 {
+			m.setBit(TF2, true)
+		}
+		if exen && fallEX {
+			m.SFR[RCAP2H] = uint8(val >> 8)
+			m.SFR[RCAP2L] = uint8(val)
+			m.setBit(EXF2, true)
+		}
+	case m.SFR[T2MOD]&t2modDCEN != 0:
+		// up/down counting by T2EX pin level, EXF2 toggle as 17th bit
+		var ovf uint64
+		if levelEX {
+			val, ovf = count16(val, rcap, n)
+		} else {
+			val, ovf = count16Down(val, rcap, n)
+		}
+		if ovf > 0 {
+			m.setBit(TF2, true)
+			if ovf%2 == 1 {
+				m.setBit(EXF2, !m.bit(EXF2))
+			}
+		}
+	default:
+		// 16-bit auto-reload, also reload on T2EX falling edge
+		var ovf uint64
+		val, ovf = count16(val, rcap, n)
+		if ovf > 0 {
+			m.setBit(TF2, true)
+		}
+		if exen && fallEX {
+			val = rcap
+			m.setBit(EXF2, true)
+		}
+	}
+	m.SFR[TH2] = uint8(val >> 8)
+	m.SFR[TL2] = uint8(val)
+}
+
+// count16 16-bit up counter reload on overflow, return new value and overflow count
+func count16(val, reload, n uint64) (uint64, uint64) {
+	val += n
+	if val <= 0xFFFF {
+		return val, 0
+	}
+	period := 0x10000 - reload
+	ovf := 1 + (val-0x10000)/period
+	return reload + (val-0x10000)%period, ovf
+}
+
+// count16Down 16-bit down counter, underflow when equal to reload and load 0xFFFF,
+// return new value and underflow count
+func count16Down(val, reload, n uint64) (uint64, uint64) {
+	dist := (val - reload) & 0xFFFF
+	if n <= dist {
+		return (val - n) & 0xFFFF, 0
+	}
+	n -= dist + 1
+	period := 0x10000 - reload
+	return 0xFFFF - n%period, 1 + n/period
+}
+
+// next machine cycles until overflow in timer mode with interrupt enabled
+func (t *timer2) next(m *Machine) uint64 {
+	if !m.bit(EA) || !m.bit(ET2) || !m.bit(TR2) || m.bit(CT2) || m.bit(RCLK) || m.bit(TCLK) {
+		return 0
+	}
+	val := uint64(m.SFR[TH2])<<8 | uint64(m.SFR[TL2])
+	if m.SFR[T2MOD]&t2modDCEN != 0 && !m.bit(CPRL2) && !t.pinT2EX {
+		rcap := uint64(m.SFR[RCAP2H])<<8 | uint64(m.SFR[RCAP2L])
+		return timerCycles(m, (val-rcap)&0xFFFF+1)
+	}
+	return timerCycles(m, 0x10000-val)
+}
+
+func (t *timer2) reset(m *Machine) {
+	t.pinT2 = true
+	t.pinT2EX = true
+}
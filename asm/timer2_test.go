@@ -0,0 +1,119 @@
+package asm_test
+
+import (
+	"testing"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+func Test_Timer2_Disabled(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = make([]byte, 0x10)
+	m.WriteBit(asm.TR2, true)
+	for i := 0; i < 0x10; i++ {
+		m.Single()
+	}
+	if m.SFR[asm.TL2] != 0 {
+		t.Errorf("8051 has no timer 2, TL2 %02X", m.SFR[asm.TL2])
+	}
+}
+
+func Test_Timer2_AutoReload(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz, asm.Variant8052)
+	m.ROM = make([]byte, 0x40)
+	copy(m.ROM[0x00:], []byte{0x02, 0x00, 0x30}) // 0000: LJMP 0030
+	copy(m.ROM[0x2B:], []byte{
+		0xC2, 0xCF, // 002B: CLR TF2
+		0x05, 0x40, // 002D: INC 0x40
+		0x32, // 002F: RETI
+	})
+	copy(m.ROM[0x30:], []byte{
+		0x75, 0xCB, 0xFF, // 0030: MOV RCAP2H, #0xFF
+		0x75, 0xCA, 0xEC, // 0033: MOV RCAP2L, #0xEC
+		0x75, 0xA8, 0xA0, // 0036: MOV IE, #0xA0, EA ET2
+		0xD2, 0xCA, // 0039: SETB TR2
+		0x80, 0xFE, // 003B: SJMP 003B
+	})
+	for m.Cycles < 208 {
+		m.Single()
+	}
+	// from 0x0000 first overflow at cycle 65544, start from RCAP2 value
+	if m.DATA[0x40] != 0 {
+		t.Errorf("timer 2 overflow early %d", m.DATA[0x40])
+	}
+
+	m.WriteDATA(asm.TH2, 0xFF)
+	m.WriteDATA(asm.TL2, 0xEC)
+	start := m.Cycles
+	for m.Cycles < start+200 {
+		m.Single()
+	}
+	// reload 0xFFEC, overflow every 20 cycles
+	if m.DATA[0x40] < 9 || m.DATA[0x40] > 10 {
+		t.Errorf("timer 2 overflow %d times in 200 cycles", m.DATA[0x40])
+	}
+}
+
+func Test_Timer2_Capture(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz, asm.Variant8052)
+	m.ROM = make([]byte, 0x100)
+	m.WriteDATA(asm.P1, 0xFF)
+	m.WriteDATA(asm.T2CON, 0x0D) // CP/RL2 TR2 EXEN2
+	for i := 0; i < 0x20; i++ {
+		m.Single()
+	}
+	m.WriteBit(0x91, false) // P1.1 T2EX falling edge
+	m.Single()
+	if !m.ReadBit(asm.EXF2) {
+		t.Errorf("EXF2 should be set on capture")
+	}
+	if m.SFR[asm.RCAP2H] != 0x00 || m.SFR[asm.RCAP2L] != 0x21 {
+		t.Errorf("capture RCAP2 %02X%02X", m.SFR[asm.RCAP2H], m.SFR[asm.RCAP2L])
+	}
+}
+
+func Test_Timer2_UpDown(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz, asm.Variant8052)
+	m.ROM = make([]byte, 0x100)
+	m.WriteDATA(asm.P1, 0xFD)    // T2EX pin low, count down
+	m.WriteDATA(asm.T2MOD, 0x01) // DCEN
+	m.WriteDATA(asm.RCAP2H, 0x12)
+	m.WriteDATA(asm.RCAP2L, 0x34)
+	m.WriteDATA(asm.TH2, 0x12)
+	m.WriteDATA(asm.TL2, 0x36)
+	m.WriteBit(asm.TR2, true)
+	m.Single()
+	m.Single()
+	if m.ReadBit(asm.TF2) || m.SFR[asm.TL2] != 0x34 {
+		t.Errorf("count down TL2 %02X", m.SFR[asm.TL2])
+	}
+	m.Single()
+	if !m.ReadBit(asm.TF2) || !m.ReadBit(asm.EXF2) || m.SFR[asm.TH2] != 0xFF || m.SFR[asm.TL2] != 0xFF {
+		t.Errorf("underflow TH2 %02X TL2 %02X", m.SFR[asm.TH2], m.SFR[asm.TL2])
+	}
+}
+
+func Test_Timer2_1T(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz, asm.VariantC8051F)
+	m.ROM = make([]byte, 0x1000)
+	m.WriteDATA(asm.TMOD, 0x01)
+	m.WriteBit(asm.TR0, true)
+	m.WriteBit(asm.TR2, true)
+	for m.Cycles < 120 {
+		m.Single() // NOP, 1 clock on 1T core
+	}
+	// timer 2 counts fosc/12 as timer 0
+	if m.SFR[asm.TL0] != 10 || m.SFR[asm.TL2] != 10 {
+		t.Errorf("TL0 %d TL2 %d after 120 clocks", m.SFR[asm.TL0], m.SFR[asm.TL2])
+	}
+
+	m.WriteDATA(asm.TL2, 0)
+	m.WriteBit(asm.RCLK, true)
+	for m.Cycles < 221 {
+		m.Single()
+	}
+	// baud rate generator counts fosc/2
+	if m.SFR[asm.TL2] != 50 {
+		t.Errorf("baud rate generator TL2 %d after 101 clocks", m.SFR[asm.TL2])
+	}
+}
@@ -0,0 +1,93 @@
+package asm_test
+
+import (
+	"testing"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+func Test_Timer_AutoReload(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = make([]byte, 0x40)
+	copy(m.ROM[0x00:], []byte{0x02, 0x00, 0x30}) // 0000: LJMP 0030
+	copy(m.ROM[0x1B:], []byte{
+		0x05, 0x40, // 001B: INC 0x40
+		0x32, // 001D: RETI
+	})
+	copy(m.ROM[0x30:], []byte{
+		0x75, 0x89, 0x20, // 0030: MOV TMOD, #0x20, timer 1 mode 2
+		0x75, 0x8D, 0xF6, // 0033: MOV TH1, #0xF6
+		0x75, 0x8B, 0xF6, // 0036: MOV TL1, #0xF6
+		0x75, 0xA8, 0x88, // 0039: MOV IE, #0x88, EA ET1
+		0xD2, 0x8E, // 003C: SETB TR1
+		0x80, 0xFE, // 003E: SJMP 003E
+	})
+
+	for m.Cycles < 1012 {
+		m.Single()
+	}
+	// TR1 set at cycle 12, overflow every 10 cycles
+	if m.DATA[0x40] < 99 || m.DATA[0x40] > 100 {
+		t.Errorf("timer 1 overflow %d times in 1000 cycles", m.DATA[0x40])
+	}
+}
+
+func Test_Timer_Modes(t *testing.T) {
+	tests := []struct {
+		name   string
+		tmod   uint8
+		th, tl uint8
+		cycles int
+		resTH  uint8
+		resTL  uint8
+		tf     bool
+	}{
+		{"mode 0", 0x00, 0xFF, 0x1E, 2, 0x00, 0x00, true},
+		{"mode 0 prescaler", 0x00, 0x12, 0xE0, 0x21, 0x13, 0xE1, false},
+		{"mode 1", 0x01, 0xFF, 0xFE, 2, 0x00, 0x00, true},
+		{"mode 1 carry", 0x01, 0x12, 0xFF, 1, 0x13, 0x00, false},
+		{"mode 2", 0x02, 0x80, 0xFF, 0x81, 0x80, 0x80, true},
+		// split timer 0, TH0 run by TR1 which is clear
+		{"mode 3", 0x03, 0x10, 0xFF, 1, 0x10, 0x00, true},
+	}
+
+	for _, tt := range tests {
+		m := asm.NewMachine(asm.Frequency1MHz)
+		m.ROM = make([]byte, tt.cycles)
+		m.WriteDATA(asm.TMOD, tt.tmod)
+		m.WriteDATA(asm.TH0, tt.th)
+		m.WriteDATA(asm.TL0, tt.tl)
+		m.WriteBit(asm.TR0, true)
+		for i := 0; i < tt.cycles; i++ {
+			m.Single() // NOP
+		}
+		if m.SFR[asm.TH0] != tt.resTH || m.SFR[asm.TL0] != tt.resTL || m.ReadBit(asm.TF0) != tt.tf {
+			t.Errorf("%s: TH0 %02X TL0 %02X TF0 %t", tt.name, m.SFR[asm.TH0], m.SFR[asm.TL0], m.ReadBit(asm.TF0))
+		}
+	}
+}
+
+func Test_Timer_Counter_Gate(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.WriteBit(0xB2, false) // P3.2 INT0 low
+	m.ROM = []byte{
+		0x75, 0x89, 0x0D, // 0000: MOV TMOD, #0x0D, timer 0 counter mode 1 with GATE
+		0xD2, 0x8C, // 0003: SETB TR0
+		0xB2, 0xB4, // 0005: CPL P3.4
+		0x80, 0xFC, // 0007: SJMP 0005
+	}
+	for i := 0; i < 2+2*20; i++ {
+		m.Single()
+	}
+	if m.SFR[asm.TL0] != 0 {
+		t.Errorf("INT0 pin low, counter should stop by GATE, TL0 %02X", m.SFR[asm.TL0])
+	}
+
+	m.WriteBit(0xB2, true) // P3.2 INT0 high
+	for i := 0; i < 2*20; i++ {
+		m.Single()
+	}
+	if m.SFR[asm.TL0] != 10 {
+		t.Errorf("T0 pin 10 falling edges, TL0 %02X", m.SFR[asm.TL0])
+	}
+}
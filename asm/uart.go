@@ -0,0 +1,245 @@
+package asm
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// uart on-chip serial port
+type uart struct {
+	w  io.Writer
+	rx io.Reader // bytes from host, polled by tick
+	// rxBit8 9th data bit of host bytes, received in mode 2 and 3
+	rxBit8 bool
+
+	txBusy bool
+	txData uint8
+	txLeft uint64 // units of baud clock until frame end
+
+	rxBusy bool
+	rxData uint8
+	rxLeft uint64
+
+	overflow1 uint64 // last seen timer 1 overflow count
+	overflow2 uint64 // last seen timer 2 overflow count
+}
+
+func newUART() *uart {
+	return &uart{}
+}
+
+// SetSerial bridge serial port to host,
+// bytes transmitted by SBUF write to w, error of w is raised as fault of the step,
+// bytes read from r are received by SBUF,
+// r is read by the machine one byte at a time when the receiver is ready, it must not block,
+// a read of no byte is retried in the next machine cycle, any error ends receiving,
+// feed it from other goroutines by SerialBuffer, set nil to detach
+func (m *Machine) SetSerial(r io.Reader, w io.Writer) {
+	m.uart.w = w
+	m.uart.rx = r
+}
+
+// SerialBuffer non-blocking serial source safe for concurrent use,
+// host write bytes to it, the machine receive them in simulated bit time
+type SerialBuffer struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	closed bool
+}
+
+// Write append bytes to be received
+func (b *SerialBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return 0, io.ErrClosedPipe
+	}
+	return b.buf.Write(p)
+}
+
+// Read read buffered bytes, 0 and nil error if empty, io.EOF after closed and drained
+func (b *SerialBuffer) Read(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.buf.Len() == 0 {
+		if b.closed {
+			return 0, io.EOF
+		}
+		return 0, nil
+	}
+	return b.buf.Read(p)
+}
+
+// Close end the bridge, buffered bytes are still received
+func (b *SerialBuffer) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	return nil
+}
+
+// SetSerialBit8 9th data bit of bytes from host in mode 2 and 3,
+// set to let the firmware with SM2 receive them as address
+func (m *Machine) SetSerialBit8(bit8 bool) {
+	m.uart.rxBit8 = bit8
+}
+
+// writeSBUF write SBUF start transmission, received data in SBUF keep untouched
+func (u *uart) writeSBUF(m *Machine, val uint8) {
+	u.txBusy = true
+	u.txData = val
+	_, period := u.clock(m, 0, false)
+	u.txLeft = u.frameBits(m) * period
+}
+
+// frameBits bits of one frame in current mode
+func (u *uart) frameBits(m *Machine) uint64 {
+	switch m.SFR[SCON] >> 6 {
+	case 0:
+		// shift register
+		return 8
+	case 1:
+		// start + 8 data + stop
+		return 10
+	}
+	// start + 8 data + 9th + stop
+	return 11
+}
+
+// clock baud clock units in this machine cycles, and units of one bit
+//
+//	mode 0: fosc/12
+//	mode 2: fosc/64, fosc/32 with SMOD
+//	mode 1, 3: timer 1 overflow/32, /16 with SMOD, or timer 2 overflow/16 by RCLK TCLK
+func (u *uart) clock(m *Machine, cycles uint64, rx bool) (uint64, uint64) {
+	smod := m.SFR[PCON]&pconSMOD != 0
+	switch m.SFR[SCON] >> 6 {
+	case 0:
+		return cycles * uint64(m.ClocksPerCycle), 12
+	case 2:
+		if smod {
+			return cycles * uint64(m.ClocksPerCycle), 32
+		}
+		return cycles * uint64(m.ClocksPerCycle), 64
+	}
+
+	if m.timer2 != nil && ((rx && m.bit(RCLK)) || (!rx && m.bit(TCLK))) {
+		return m.timer2.overflow - u.overflow2, 16
+	}
+	if smod {
+		return m.timer.overflow1 - u.overflow1, 16
+	}
+	return m.timer.overflow1 - u.overflow1, 32
+}
+
+func (u *uart) tick(m *Machine, cycles uint64) {
+	if u.txBusy {
+		n, _ := u.clock(m, cycles, false)
+		if n >= u.txLeft {
+			u.txBusy = false
+			if u.w != nil {
+				if _, err := u.w.Write([]byte{u.txData}); err != nil {
+					m.Fault(fmt.Errorf("serial write: %w", err))
+				}
+			}
+			m.setBit(TI, true)
+		} else {
+			u.txLeft -= n
+		}
+	}
+
+	if u.rxBusy {
+		n, _ := u.clock(m, cycles, true)
+		if n >= u.rxLeft {
+			u.rxBusy = false
+			u.receive(m)
+		} else {
+			u.rxLeft -= n
+		}
+	} else if m.bit(REN) && u.rx != nil && (m.SFR[SCON]>>6 != 0 || !m.bit(RI)) {
+		// mode 0 receive is started by REN = 1 and RI = 0
+		var b [1]byte
+		n, err := u.rx.Read(b[:])
+		if n == 1 {
+			_, period := u.clock(m, 0, true)
+			u.rxBusy = true
+			u.rxData = b[0]
+			u.rxLeft = u.frameBits(m) * period
+		}
+		if err != nil {
+			u.rx = nil
+		}
+	}
+
+	if m.timer2 != nil {
+		u.overflow2 = m.timer2.overflow
+	}
+	u.overflow1 = m.timer.overflow1
+}
+
+// receive frame end, load SBUF and set RI
+func (u *uart) receive(m *Machine) {
+	if m.bit(RI) {
+		// overrun, frame lost
+		return
+	}
+	switch m.SFR[SCON] >> 6 {
+	case 0:
+	case 1:
+		// RB8 is stop bit, always valid here
+		m.setBit(RB8, true)
+	default:
+		if m.bit(SM2) && !u.rxBit8 {
+			// multiprocessor mode only receive address frame
+			return
+		}
+		m.setBit(RB8, u.rxBit8)
+	}
+	m.SFR[SBUF] = u.rxData
+	m.setBit(RI, true)
+}
+
+// next machine cycles until frame end, 1 when not predictable
+func (u *uart) next(m *Machine) uint64 {
+	var n uint64
+	if u.txBusy {
+		n = minEvent(n, u.left(m, u.txLeft, false))
+	}
+	if u.rxBusy {
+		n = minEvent(n, u.left(m, u.rxLeft, true))
+	} else if m.bit(REN) && u.rx != nil {
+		// host bytes may arrive any time
+		n = 1
+	}
+	return n
+}
+
+// left machine cycles until units of baud clock are used up
+func (u *uart) left(m *Machine, units uint64, rx bool) uint64 {
+	switch m.SFR[SCON] >> 6 {
+	case 0, 2:
+		c := uint64(m.ClocksPerCycle)
+		return (units + c - 1) / c
+	}
+	if m.timer2 != nil && ((rx && m.bit(RCLK)) || (!rx && m.bit(TCLK))) {
+		return 1
+	}
+	// timer 1 mode 2 counting machine cycles, the common baud rate generator
+	if (m.SFR[TMOD]>>4)&(tmodM0|tmodM1) != 2 || !m.timer.timing(m, 1, m.bit(TR1)) ||
+		m.SFR[TMOD]&(tmodM0|tmodM1) == 3 {
+		return 1
+	}
+	return timerCycles(m, 0x100-uint64(m.SFR[TL1])+(units-1)*(0x100-uint64(m.SFR[TH1])))
+}
+
+// reset abort frames in progress, host bridge is kept
+func (u *uart) reset(m *Machine) {
+	u.txBusy = false
+	u.rxBusy = false
+	u.overflow1 = m.timer.overflow1
+	if m.timer2 != nil {
+		u.overflow2 = m.timer2.overflow
+	}
+}
@@ -0,0 +1,117 @@
+package asm_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+func Test_UART_Echo(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.Crystal = asm.Crystal11_0592MHz
+	m.ROM = []byte{
+		0x75, 0x98, 0x50, // 0000: MOV SCON, #0x50, mode 1 REN
+		0x75, 0x89, 0x20, // 0003: MOV TMOD, #0x20, timer 1 mode 2
+		0x75, 0x8D, 0xFD, // 0006: MOV TH1, #0xFD, 9600 baud
+		0x75, 0x8B, 0xFD, // 0009: MOV TL1, #0xFD
+		0xD2, 0x8E, // 000C: SETB TR1
+		0x30, 0x98, 0xFD, // 000E: JNB RI, 000E
+		0xC2, 0x98, // 0011: CLR RI
+		0xE5, 0x99, // 0013: MOV A, SBUF
+		0x04,       // 0015: INC A
+		0xF5, 0x99, // 0016: MOV SBUF, A
+		0x30, 0x99, 0xFD, // 0018: JNB TI, 0018
+		0xC2, 0x99, // 001B: CLR TI
+		0x80, 0xEF, // 001D: SJMP 000E
+	}
+
+	out := &bytes.Buffer{}
+	m.SetSerial(strings.NewReader("ABC"), out)
+	for out.Len() < 3 && m.Cycles < 1000000 {
+		m.Single()
+	}
+
+	if out.String() != "BCD" {
+		t.Fatalf("serial output %q", out.String())
+	}
+	// 9600 baud 10 bits frame, 3 bytes in back to back, last echo sent after the last byte in
+	if d := m.Time().Seconds(); d < 4*10/9600.0 || d > 5*10/9600.0 {
+		t.Errorf("serial time %fs", d)
+	}
+}
+
+func Test_UART_SerialBuffer(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x75, 0x98, 0x50, // 0000: MOV SCON, #0x50, mode 1 REN
+		0x75, 0x89, 0x20, // 0003: MOV TMOD, #0x20, timer 1 mode 2
+		0x75, 0x8D, 0xFF, // 0006: MOV TH1, #0xFF, 32 cycles a bit
+		0xD2, 0x8E, // 0009: SETB TR1
+		0x80, 0xFE, // 000B: SJMP $
+	}
+	in := &asm.SerialBuffer{}
+	m.SetSerial(in, nil)
+	m.RunCycles(1000)
+	if m.ReadBit(asm.RI) {
+		t.Fatalf("received from empty buffer")
+	}
+
+	in.Write([]byte{0x42})
+	start := m.Cycles
+	m.RunUntil(func(m *asm.Machine) bool { return m.ReadBit(asm.RI) })
+	if d := m.Cycles - start; d < 10*32 || d > 10*32+4 {
+		t.Errorf("receive %d cycles", d)
+	}
+	if m.SFR[asm.SBUF] != 0x42 {
+		t.Errorf("SBUF %02X", m.SFR[asm.SBUF])
+	}
+
+	in.Close()
+	if _, err := in.Write([]byte{0x43}); err == nil {
+		t.Errorf("write after close")
+	}
+}
+
+func Test_UART_Mode0(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x75, 0x99, 0x5A, // 0000: MOV SBUF, #0x5A, mode 0
+		0x30, 0x99, 0xFD, // 0003: JNB TI, 0003
+	}
+	out := &bytes.Buffer{}
+	m.SetSerial(nil, out)
+	start := m.Cycles
+	m.Single()
+	for !m.ReadBit(asm.TI) {
+		m.Single()
+	}
+	// 8 bits shift out at fosc/12
+	if d := m.Cycles - start; d < 8 || d > 10 {
+		t.Errorf("mode 0 transmit %d cycles", d)
+	}
+	if out.String() != "\x5A" {
+		t.Errorf("serial output %q", out.String())
+	}
+	if m.SFR[asm.SBUF] != 0x00 {
+		t.Errorf("SBUF write should not change receive buffer, %02X", m.SFR[asm.SBUF])
+	}
+}
+
+func Test_UART_WriteError(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x75, 0x99, 0x5A, // 0000: MOV SBUF, #0x5A, mode 0
+		0x80, 0xFE, // 0003: SJMP $
+	}
+	out := &asm.SerialBuffer{}
+	out.Close()
+	m.SetSerial(nil, out)
+	h := m.RunCycles(100)
+	if h.Reason != asm.HaltError || !errors.Is(h.Err, io.ErrClosedPipe) || !m.ReadBit(asm.TI) {
+		t.Errorf("write error %s", h)
+	}
+}
@@ -0,0 +1,258 @@
+package asm
+
+// ResetFlag SFR bit records reset cause
+type ResetFlag struct {
+	Addr  uint8
+	Mask  uint8
+	Cause ResetCause
+	// Sticky kept by reset of other causes, cleared by software only
+	Sticky bool
+}
+
+// Variant device profile of 8051 derivative
+type Variant struct {
+	Name     string
+	ROMSize  int // internal CODE size, 0 on ROMless part, fetch from PC beyond it is on external bus
+	IRAMSize int // internal DATA and IDATA size, 128 or 256
+	XRAMSize int // on-chip XDATA size, MOVX beyond it is on external bus
+
+	ClocksPerCycle uint // clocks per machine cycle, 12T, 6T or 1T core
+	// TimerClocks clocks per timer count, 0: same as machine cycle,
+	// 1T derivatives keep timers at fosc/12 by default
+	TimerClocks uint
+	Crystal     uint // default crystal frequency in Hz
+
+	SFRs        []Register      // SFR map
+	ResetValues map[uint8]uint8 // SFR reset values which are not zero
+	ResetFlags  []ResetFlag
+	Interrupts  []Interrupt // interrupt sources, in polling order
+	Timer2      bool        // 8052 timer 2
+}
+
+func joinRegs(lists ...[]Register) []Register {
+	var regs []Register
+	for _, l := range lists {
+		regs = append(regs, l...)
+	}
+	return regs
+}
+
+func joinInterrupts(lists ...[]Interrupt) []Interrupt {
+	var list []Interrupt
+	for _, l := range lists {
+		list = append(list, l...)
+	}
+	return list
+}
+
+// withReset sfrReset with extra reset values
+func withReset(vals map[uint8]uint8) map[uint8]uint8 {
+	m := map[uint8]uint8{}
+	for addr, val := range sfrReset {
+		m[addr] = val
+	}
+	for addr, val := range vals {
+		m[addr] = val
+	}
+	return m
+}
+
+// pconPOF power-off flag of Atmel and STC derivatives, set by power-on reset
+const pconPOF uint8 = 1 << 4
+
+// Variant8031 ROMless 8031, program in external CODE
+var Variant8031 = Variant{
+	Name:           "8031",
+	IRAMSize:       0x80,
+	ClocksPerCycle: 12,
+	Crystal:        Crystal12MHz,
+	SFRs:           regList,
+	ResetValues:    sfrReset,
+	Interrupts:     interruptList,
+}
+
+// Variant8051 classic 8051
+var Variant8051 = Variant{
+	Name:           "8051",
+	ROMSize:        0x1000,
+	IRAMSize:       0x80,
+	ClocksPerCycle: 12,
+	Crystal:        Crystal12MHz,
+	SFRs:           regList,
+	ResetValues:    sfrReset,
+	Interrupts:     interruptList,
+}
+
+// Variant8052 8052 with timer 2 and 256 bytes internal RAM
+var Variant8052 = Variant{
+	Name:           "8052",
+	ROMSize:        0x2000,
+	IRAMSize:       0x100,
+	ClocksPerCycle: 12,
+	Crystal:        Crystal12MHz,
+	SFRs:           joinRegs(regList, regList8052),
+	ResetValues:    sfrReset,
+	Interrupts:     joinInterrupts(interruptList, []Interrupt{interruptTimer2}),
+	Timer2:         true,
+}
+
+// VariantAT89S52 Atmel AT89S52
+var VariantAT89S52 = Variant{
+	Name:           "AT89S52",
+	ROMSize:        0x2000,
+	IRAMSize:       0x100,
+	ClocksPerCycle: 12,
+	Crystal:        Crystal12MHz,
+	SFRs: joinRegs(regList, regList8052, []Register{
+		{0x84, "DP1L"},
+		{0x85, "DP1H"},
+		{0x8E, "AUXR"},
+		{0xA2, "AUXR1"},
+		{0xA6, "WDTRST"},
+	}),
+	ResetValues: sfrReset,
+	ResetFlags: []ResetFlag{
+		{Addr: PCON, Mask: pconPOF, Cause: ResetPowerOn, Sticky: true},
+	},
+	Interrupts: joinInterrupts(interruptList, []Interrupt{interruptTimer2}),
+	Timer2:     true,
+}
+
+// VariantSTC89C52 STC89C52RC, 12T mode
+var VariantSTC89C52 = Variant{
+	Name:           "STC89C52",
+	ROMSize:        0x2000,
+	IRAMSize:       0x100,
+	XRAMSize:       0x100,
+	ClocksPerCycle: 12,
+	Crystal:        Crystal11_0592MHz,
+	SFRs: joinRegs(regList, regList8052, []Register{
+		{0x8E, "AUXR"},
+		{0xA2, "AUXR1"},
+		{0xB7, "IPH"},
+		{0xC0, "XICON"},
+		{0xE1, "WDT_CONTR"},
+		{0xE2, "ISP_DATA"},
+		{0xE3, "ISP_ADDRH"},
+		{0xE4, "ISP_ADDRL"},
+		{0xE5, "ISP_CMD"},
+		{0xE6, "ISP_TRIG"},
+		{0xE7, "ISP_CONTR"},
+		{0xE8, "P4"},
+	}),
+	ResetValues: withReset(map[uint8]uint8{0xE8: 0xFF}),
+	ResetFlags: []ResetFlag{
+		{Addr: PCON, Mask: pconPOF, Cause: ResetPowerOn, Sticky: true},
+	},
+	Interrupts: joinInterrupts(interruptList, []Interrupt{
+		interruptTimer2,
+		// XICON: IT2 0xC0, IE2 0xC1, EX2 0xC2, PX2 0xC3, IT3 0xC4, IE3 0xC5, EX3 0xC6, PX3 0xC7
+		{Name: "INT2", Vector: 0x0033, Enable: ByBit(0xC2), Priority: ByBit(0xC3), Flags: []uint8{0xC1}, Ack: func(m *Machine) {
+			if m.ReadBit(0xC0) {
+				m.WriteBit(0xC1, false)
+			}
+		}},
+		{Name: "INT3", Vector: 0x003B, Enable: ByBit(0xC6), Priority: ByBit(0xC7), Flags: []uint8{0xC5}, Ack: func(m *Machine) {
+			if m.ReadBit(0xC4) {
+				m.WriteBit(0xC5, false)
+			}
+		}},
+	}),
+	Timer2: true,
+}
+
+// VariantSTC15 STC15F2K60S2, 1T core,
+// its timer 2 and extra peripherals are not modeled, SFRs are plain storage
+var VariantSTC15 = Variant{
+	Name:           "STC15",
+	ROMSize:        0xF000,
+	IRAMSize:       0x100,
+	XRAMSize:       0x800,
+	ClocksPerCycle: 1,
+	TimerClocks:    12,
+	Crystal:        Crystal11_0592MHz,
+	SFRs: joinRegs(regList, []Register{
+		{0x8E, "AUXR"},
+		{0x8F, "INT_CLKO"},
+		{0x91, "P1M1"},
+		{0x92, "P1M0"},
+		{0x93, "P0M1"},
+		{0x94, "P0M0"},
+		{0x95, "P2M1"},
+		{0x96, "P2M0"},
+		{0x97, "CLK_DIV"},
+		{0x9A, "S2CON"},
+		{0x9B, "S2BUF"},
+		{0xA2, "P_SW1"},
+		{0xAF, "IE2"},
+		{0xB1, "P3M1"},
+		{0xB2, "P3M0"},
+		{0xBC, "ADC_CONTR"},
+		{0xBD, "ADC_RES"},
+		{0xBE, "ADC_RESL"},
+		{0xC0, "P4"},
+		{0xC1, "WDT_CONTR"},
+		{0xC2, "IAP_DATA"},
+		{0xC3, "IAP_ADDRH"},
+		{0xC4, "IAP_ADDRL"},
+		{0xC5, "IAP_CMD"},
+		{0xC6, "IAP_TRIG"},
+		{0xC7, "IAP_CONTR"},
+		{0xC8, "P5"},
+		{0xD6, "T2H"},
+		{0xD7, "T2L"},
+	}),
+	ResetValues: withReset(map[uint8]uint8{0xC0: 0xFF, 0xC8: 0xFF}),
+	ResetFlags: []ResetFlag{
+		{Addr: PCON, Mask: pconPOF, Cause: ResetPowerOn, Sticky: true},
+		// WDT_CONTR.WDT_FLAG
+		{Addr: 0xC1, Mask: 0x80, Cause: ResetWatchdog, Sticky: true},
+	},
+	Interrupts: interruptList,
+}
+
+// VariantC8051F Silicon Labs C8051F020, 1T CIP-51 core,
+// crossbar and analog peripherals are not modeled, SFRs are plain storage
+var VariantC8051F = Variant{
+	Name:           "C8051F020",
+	ROMSize:        0x10000,
+	IRAMSize:       0x100,
+	XRAMSize:       0x1000,
+	ClocksPerCycle: 1,
+	TimerClocks:    12,
+	Crystal:        2000000, // internal oscillator
+	SFRs: joinRegs(regList, regList8052, []Register{
+		{0x84, "P4"},
+		{0x85, "P5"},
+		{0x86, "P6"},
+		{0x8E, "CKCON"},
+		{0x8F, "PSCTL"},
+		{0x96, "P7"},
+		{0xA4, "P0MDOUT"},
+		{0xA5, "P1MDOUT"},
+		{0xA6, "P2MDOUT"},
+		{0xA7, "P3MDOUT"},
+		{0xAF, "EMI0CN"},
+		{0xB1, "OSCXCN"},
+		{0xB2, "OSCICN"},
+		{0xE1, "XBR0"},
+		{0xE2, "XBR1"},
+		{0xE3, "XBR2"},
+		{0xE6, "EIE1"},
+		{0xE7, "EIE2"},
+		{0xEF, "RSTSRC"},
+		{0xF6, "EIP1"},
+		{0xF7, "EIP2"},
+		{0xFF, "WDTCN"},
+	}),
+	ResetValues: withReset(map[uint8]uint8{0x84: 0xFF, 0x85: 0xFF, 0x86: 0xFF, 0x96: 0xFF, 0xB2: 0x14}),
+	ResetFlags: []ResetFlag{
+		// RSTSRC: PINRSF, PORSF, WDTRSF, SWRSF
+		{Addr: 0xEF, Mask: 0x01, Cause: ResetExternal},
+		{Addr: 0xEF, Mask: 0x02, Cause: ResetPowerOn},
+		{Addr: 0xEF, Mask: 0x08, Cause: ResetWatchdog},
+		{Addr: 0xEF, Mask: 0x10, Cause: ResetSoftware},
+	},
+	Interrupts: joinInterrupts(interruptList, []Interrupt{interruptTimer2}),
+	Timer2:     true,
+}
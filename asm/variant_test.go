@@ -0,0 +1,132 @@
+package asm_test
+
+import (
+	"testing"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+func Test_Variant_IDATA(t *testing.T) {
+	rom := []byte{
+		0x78, 0x90, // 0000: MOV R0, #0x90
+		0x76, 0x5A, // 0002: MOV @R0, #0x5A
+		0xE6, // 0004: MOV A, @R0
+	}
+	tests := []struct {
+		v   asm.Variant
+		acc uint8
+	}{
+		{asm.Variant8051, 0xFF},
+		{asm.Variant8052, 0x5A},
+		{asm.VariantSTC89C52, 0x5A},
+	}
+	for _, tt := range tests {
+		m := asm.NewMachine(asm.Frequency1MHz, tt.v)
+		m.ROM = rom
+		for m.PC < uint(len(m.ROM)) {
+			m.Single()
+		}
+		if m.SFR[asm.ACC] != tt.acc {
+			t.Errorf("%s: @R0 read %02X", tt.v.Name, m.SFR[asm.ACC])
+		}
+	}
+}
+
+func Test_Variant_TimerClocks(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz, asm.VariantSTC15)
+	m.ROM = make([]byte, 0x1000)
+	m.WriteDATA(asm.TMOD, 0x01)
+	m.WriteBit(asm.TR0, true)
+	for m.Cycles < 1200 {
+		m.Single() // NOP, 1 clock on 1T core
+	}
+	// timer 0 counts fosc/12
+	if m.SFR[asm.TL0] != 100 {
+		t.Errorf("TL0 %d after 1200 clocks", m.SFR[asm.TL0])
+	}
+	if asm.FindRegByName("P4", m.Variant.SFRs) == nil {
+		t.Errorf("P4 not defined")
+	}
+}
+
+func Test_Variant_ResetFlags(t *testing.T) {
+	const rstsrc = 0xEF
+	m := asm.NewMachine(asm.Frequency1MHz, asm.VariantC8051F)
+	if m.SFR[rstsrc] != 0x02 {
+		t.Errorf("power-on RSTSRC %02X", m.SFR[rstsrc])
+	}
+	m.ResetBy(asm.ResetWatchdog)
+	if m.SFR[rstsrc] != 0x08 {
+		t.Errorf("watchdog RSTSRC %02X", m.SFR[rstsrc])
+	}
+
+	m = asm.NewMachine(asm.Frequency1MHz, asm.VariantSTC89C52)
+	if m.SFR[asm.PCON] != 0x10 || m.SFR[0xE8] != 0xFF {
+		t.Errorf("power-on PCON %02X P4 %02X", m.SFR[asm.PCON], m.SFR[0xE8])
+	}
+	m.Reset()
+	if m.SFR[asm.PCON] != 0x10 {
+		t.Errorf("POF should be kept by external reset, PCON %02X", m.SFR[asm.PCON])
+	}
+	m.WriteDATA(asm.PCON, 0x00)
+	m.Reset()
+	if m.SFR[asm.PCON] != 0x00 {
+		t.Errorf("POF cleared by software, PCON %02X", m.SFR[asm.PCON])
+	}
+}
+
+func Test_Variant_ExternalBus(t *testing.T) {
+	rom := []byte{
+		0x75, 0x80, 0x00, // 0000: MOV P0, #0x00
+		0x00, // 0003: NOP
+	}
+	for _, tt := range []struct {
+		v  asm.Variant
+		p0 uint8
+	}{
+		{asm.Variant8031, 0xFF}, // external fetch of NOP on P0 bus
+		{asm.Variant8051, 0x00},
+	} {
+		m := asm.NewMachine(asm.Frequency1MHz, tt.v)
+		m.ROM = rom
+		m.RunInstructions(2)
+		if m.SFR[asm.P0] != tt.p0 {
+			t.Errorf("%s: P0 %02X", tt.v.Name, m.SFR[asm.P0])
+		}
+	}
+
+	m := asm.NewMachine(asm.Frequency1MHz, asm.VariantSTC89C52)
+	m.ROM = []byte{
+		0x75, 0x80, 0x00, // 0000: MOV P0, #0x00
+		0x90, 0x00, 0xFF, // 0003: MOV DPTR, #0x00FF
+		0xF0, // 0006: MOVX @DPTR, A, on-chip XRAM
+		0xA3, // 0007: INC DPTR
+		0xF0, // 0008: MOVX @DPTR, A, external
+	}
+	m.RunInstructions(3)
+	if m.SFR[asm.P0] != 0x00 {
+		t.Errorf("on-chip XRAM P0 %02X", m.SFR[asm.P0])
+	}
+	m.RunInstructions(2)
+	if m.SFR[asm.P0] != 0xFF {
+		t.Errorf("external XDATA P0 %02X", m.SFR[asm.P0])
+	}
+
+	// default flat machine has no external bus, banked code and MOVX keep P0
+	m = asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = make([]byte, 0x18000)
+	copy(m.ROM, []byte{
+		0x75, 0x80, 0x00, // 0000: MOV P0, #0x00
+		0x75, 0x90, 0xFD, // 0003: MOV P1, #0xFD, bank 1
+		0x02, 0x80, 0x00, // 0006: LJMP 8000
+	})
+	copy(m.ROM[0x10000:], []byte{
+		0x90, 0xFF, 0x00, // 01:8000: MOV DPTR, #0xFF00
+		0xF0, // 01:8003: MOVX @DPTR, A
+	})
+	m.SetBanking(&asm.Banking{Window: 0x8000, Bank: asm.BankSFR(asm.P1, 0x03)})
+	m.RunInstructions(5)
+	if m.PC != 0x8004 || m.SFR[asm.P0] != 0x00 {
+		t.Errorf("default machine PC %04X P0 %02X", m.PC, m.SFR[asm.P0])
+	}
+}
@@ -0,0 +1,227 @@
+package asm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// Space memory space of watchpoint
+type Space int
+
+const (
+	// SpaceDATA internal RAM 0x00~0x7F, by direct or indirect addressing,
+	// range beyond 0x7F never hit, use SpaceIDATA for upper internal RAM
+	SpaceDATA Space = iota
+	// SpaceIDATA internal RAM 0x00~0xFF, by direct or indirect addressing
+	SpaceIDATA
+	// SpaceSFR SFR 0x80~0xFF by direct addressing
+	SpaceSFR
+	// SpaceXDATA XDATA by MOVX
+	SpaceXDATA
+	// SpaceCODE CODE by MOVC
+	SpaceCODE
+)
+
+func (s Space) String() string {
+	switch s {
+	case SpaceDATA:
+		return "DATA"
+	case SpaceIDATA:
+		return "IDATA"
+	case SpaceSFR:
+		return "SFR"
+	case SpaceXDATA:
+		return "XDATA"
+	}
+	return "CODE"
+}
+
+// MarshalText space name in JSON
+func (s Space) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText space by name in JSON
+func (s *Space) UnmarshalText(text []byte) error {
+	for v := SpaceDATA; v <= SpaceCODE; v++ {
+		if v.String() == string(text) {
+			*s = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown memory space %q", text)
+}
+
+// WatchKind access kinds of watchpoint, bitmask
+type WatchKind int
+
+const (
+	// WatchRead read access
+	WatchRead WatchKind = 1 << iota
+	// WatchWrite write access
+	WatchWrite
+	// WatchChange write access changing the value
+	WatchChange
+)
+
+func (k WatchKind) String() string {
+	var s []string
+	if k&WatchRead != 0 {
+		s = append(s, "Read")
+	}
+	if k&WatchWrite != 0 {
+		s = append(s, "Write")
+	}
+	if k&WatchChange != 0 {
+		s = append(s, "Change")
+	}
+	return strings.Join(s, "|")
+}
+
+// Watchpoint memory watchpoint on address range, by instruction access,
+// watchpoints can be managed by other goroutines while the run loop is running
+type Watchpoint struct {
+	ID         int // assigned by AddWatchpoint
+	Space      Space
+	Start, End uint // address range, End included, ROM offset for SpaceCODE
+	Kind       WatchKind
+	Disabled   bool
+	// Filter value filter of read value or written value, nil always true
+	Filter func(val uint8) bool
+	// Halt halt execution after the accessing instruction
+	Halt bool
+	// Action called on each hit
+	Action func(m *Machine, hit WatchHit)
+	// Hits times hit
+	Hits int
+}
+
+// WatchHit access hit watchpoint
+type WatchHit struct {
+	ID    int
+	Space Space
+	Addr  uint      // ROM offset for SpaceCODE
+	Kind  WatchKind // WatchRead, WatchWrite or WatchChange
+	Old   uint8     // value before write
+	Value uint8     // value read or written
+	PC    uint      // PC of the accessing instruction
+}
+
+// AddWatchpoint add watchpoint, return its ID
+func (m *Machine) AddWatchpoint(w Watchpoint) int {
+	c := m.ctl
+	c.debug.Lock()
+	defer c.debug.Unlock()
+	c.nextWatchID++
+	w.ID = c.nextWatchID
+	n := len(c.watchpoints)
+	c.watchpoints = append(c.watchpoints[:n:n], &w)
+	atomic.StoreInt32(&c.watching, int32(len(c.watchpoints)))
+	return w.ID
+}
+
+// RemoveWatchpoint remove watchpoint by ID, false if not found
+func (m *Machine) RemoveWatchpoint(id int) bool {
+	c := m.ctl
+	c.debug.Lock()
+	defer c.debug.Unlock()
+	for k, w := range c.watchpoints {
+		if w.ID == id {
+			c.watchpoints = append(c.watchpoints[:k:k], c.watchpoints[k+1:]...)
+			atomic.StoreInt32(&c.watching, int32(len(c.watchpoints)))
+			return true
+		}
+	}
+	return false
+}
+
+// EnableWatchpoint enable or disable watchpoint by ID, false if not found
+func (m *Machine) EnableWatchpoint(id int, enabled bool) bool {
+	c := m.ctl
+	c.debug.Lock()
+	defer c.debug.Unlock()
+	for _, w := range c.watchpoints {
+		if w.ID == id {
+			w.Disabled = !enabled
+			return true
+		}
+	}
+	return false
+}
+
+// Watchpoints copy of all watchpoints, by ID order
+func (m *Machine) Watchpoints() []Watchpoint {
+	c := m.ctl
+	c.debug.Lock()
+	var list []Watchpoint
+	for _, w := range c.watchpoints {
+		list = append(list, *w)
+	}
+	c.debug.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// watchSpace watchpoint space match access, DATA and IDATA are the same internal RAM,
+// DATA limited to the lower 128 bytes
+func watchSpace(w, access Space, addr uint) bool {
+	if access == SpaceDATA || access == SpaceIDATA {
+		return w == SpaceIDATA || (w == SpaceDATA && addr < 0x80)
+	}
+	return w == access
+}
+
+// watch check access by executing instruction,
+// Filter and Action are called without c.debug held, they may manage watchpoints
+func (m *Machine) watch(space Space, addr uint, kind WatchKind, old, val uint8) {
+	if !m.executing {
+		return
+	}
+	if m.recorder != nil && kind == WatchWrite {
+		m.recorder.write(space, addr, old, val)
+	}
+	c := m.ctl
+	if atomic.LoadInt32(&c.watching) == 0 {
+		return
+	}
+	if kind == WatchWrite && old != val {
+		kind |= WatchChange
+	}
+	c.debug.Lock()
+	list := c.watchpoints
+	c.debug.Unlock()
+	for _, w := range list {
+		c.debug.Lock()
+		disabled := w.Disabled
+		c.debug.Unlock()
+		if disabled || !watchSpace(w.Space, space, addr) || addr < w.Start || addr > w.End {
+			continue
+		}
+		hit := w.Kind & kind
+		if hit == 0 || (w.Filter != nil && !w.Filter(val)) {
+			continue
+		}
+		if hit&WatchChange != 0 {
+			hit = WatchChange
+		}
+		c.debug.Lock()
+		w.Hits++
+		c.debug.Unlock()
+		h := WatchHit{ID: w.ID, Space: space, Addr: addr, Kind: hit, Old: old, Value: val, PC: m.insPC}
+		if w.Action != nil {
+			w.Action(m, h)
+		}
+		if w.Halt && m.watchHit == nil {
+			m.watchHit = &h
+		}
+	}
+}
+
+// takeWatchHit halting watchpoint hit in this step
+func (m *Machine) takeWatchHit() *WatchHit {
+	h := m.watchHit
+	m.watchHit = nil
+	return h
+}
@@ -0,0 +1,129 @@
+package asm_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+func Test_Watchpoint(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz, asm.Variant8052)
+	m.ROM = []byte{
+		0x75, 0x30, 0x05, // 0000: MOV 30H, #05H
+		0x75, 0x30, 0x05, // 0003: MOV 30H, #05H
+		0x78, 0x90, // 0006: MOV R0, #90H
+		0xF6,             // 0008: MOV @R0, A
+		0x90, 0x12, 0x34, // 0009: MOV DPTR, #1234H
+		0xF0,       // 000C: MOVX @DPTR, A
+		0xE4,       // 000D: CLR A
+		0x93,       // 000E: MOVC A, @A+DPTR
+		0x80, 0xFE, // 000F: SJMP $
+	}
+	m.ROM = append(m.ROM, make([]byte, 0x1234-len(m.ROM))...)
+	m.ROM = append(m.ROM, 0xA5)
+	m.WriteDATA(asm.ACC, 0x77)
+
+	var hits []asm.WatchHit
+	record := func(m *asm.Machine, hit asm.WatchHit) { hits = append(hits, hit) }
+	m.AddWatchpoint(asm.Watchpoint{Space: asm.SpaceDATA, Start: 0x30, End: 0x30, Kind: asm.WatchWrite, Action: record})
+	change := m.AddWatchpoint(asm.Watchpoint{Space: asm.SpaceDATA, Start: 0x30, End: 0x30, Kind: asm.WatchChange, Action: record})
+	// DATA is the lower 128 bytes only, the write at 0x90 is by IDATA watchpoint
+	m.AddWatchpoint(asm.Watchpoint{Space: asm.SpaceDATA, Start: 0x80, End: 0xFF, Kind: asm.WatchWrite, Halt: true})
+	m.AddWatchpoint(asm.Watchpoint{Space: asm.SpaceIDATA, Start: 0x80, End: 0xFF, Kind: asm.WatchWrite, Halt: true})
+	m.AddWatchpoint(asm.Watchpoint{
+		Space: asm.SpaceXDATA, Start: 0x1200, End: 0x12FF, Kind: asm.WatchWrite | asm.WatchRead,
+		Filter: func(val uint8) bool { return val == 0x77 },
+		Halt:   true,
+	})
+	m.AddWatchpoint(asm.Watchpoint{Space: asm.SpaceCODE, Start: 0x1234, End: 0x1234, Kind: asm.WatchRead, Halt: true})
+	// SFR 0x90 is P1, not upper IDATA
+	m.AddWatchpoint(asm.Watchpoint{Space: asm.SpaceSFR, Start: 0x90, End: 0x90, Kind: asm.WatchRead | asm.WatchWrite, Halt: true})
+
+	h := m.RunCycles(100)
+	if h.Reason != asm.HaltWatchpoint || h.PC != 0x09 || h.Watch.Space != asm.SpaceIDATA ||
+		h.Watch.Addr != 0x90 || h.Watch.Value != 0x77 || h.Watch.PC != 0x08 {
+		t.Fatalf("IDATA %s %+v", h, h.Watch)
+	}
+	if len(hits) != 3 || hits[0].ID != 1 || hits[1].ID != change || hits[1].Old != 0 || hits[2].PC != 0x03 {
+		t.Errorf("DATA hits %+v", hits)
+	}
+	if wps := m.Watchpoints(); wps[0].Hits != 2 || wps[1].Hits != 1 || wps[2].Hits != 0 || wps[3].Hits != 1 {
+		t.Errorf("hits %d %d %d %d", wps[0].Hits, wps[1].Hits, wps[2].Hits, wps[3].Hits)
+	}
+
+	h = m.RunCycles(100)
+	if h.Reason != asm.HaltWatchpoint || h.Watch.Space != asm.SpaceXDATA || h.Watch.Addr != 0x1234 ||
+		h.Watch.Kind != asm.WatchWrite || h.Watch.PC != 0x0C || m.XDATA[0x1234] != 0x77 {
+		t.Fatalf("XDATA %s %+v", h, h.Watch)
+	}
+
+	h = m.RunCycles(100)
+	if h.Reason != asm.HaltWatchpoint || h.Watch.Space != asm.SpaceCODE || h.Watch.Value != 0xA5 ||
+		h.Watch.PC != 0x0E || m.ReadDATA(asm.ACC) != 0xA5 {
+		t.Fatalf("CODE %s %+v", h, h.Watch)
+	}
+
+	if h := m.RunCycles(100); h.Reason != asm.HaltLimit {
+		t.Errorf("after %s", h)
+	}
+	if !m.EnableWatchpoint(change, false) || !m.RemoveWatchpoint(change) || m.RemoveWatchpoint(change) {
+		t.Errorf("manage watchpoint")
+	}
+}
+
+func Test_Watchpoint_Single(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	m.ROM = []byte{
+		0x00,       // 0000: NOP
+		0x05, 0x30, // 0001: INC 30H
+		0x00, // 0003: NOP
+	}
+	m.AddWatchpoint(asm.Watchpoint{Space: asm.SpaceDATA, Start: 0x30, End: 0x30, Kind: asm.WatchWrite, Halt: true})
+	if err := m.Single(); err != nil {
+		t.Fatal(err)
+	}
+	err := m.Single()
+	var we *asm.WatchError
+	if !errors.Is(err, asm.ErrWatch) || !errors.As(err, &we) || we.Hit.Addr != 0x30 || we.Hit.PC != 0x01 ||
+		m.PC != 0x03 || m.DATA[0x30] != 1 {
+		t.Fatalf("watch %v, PC %04X", err, m.PC)
+	}
+	if err := m.Single(); err != nil || m.PC != 0x04 {
+		t.Errorf("after watch %v, PC %04X", err, m.PC)
+	}
+}
+
+func Test_Watchpoint_WhileRunning(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	m := asm.NewMachine(time.Microsecond)
+	m.ROM = []byte{
+		0x05, 0x30, // 0000: INC 30H
+		0x80, 0xFC, // 0002: SJMP 0000
+	}
+	m.Start(ctx)
+	if err := m.WaitState(ctx, asm.StateRunning); err != nil {
+		t.Fatal(err)
+	}
+	for k := 0; k < 100; k++ {
+		id := m.AddWatchpoint(asm.Watchpoint{Space: asm.SpaceDATA, Start: 0x30, End: 0x30, Kind: asm.WatchWrite})
+		m.EnableWatchpoint(id, false)
+		m.Watchpoints()
+		m.RemoveWatchpoint(id)
+	}
+	m.AddWatchpoint(asm.Watchpoint{Space: asm.SpaceDATA, Start: 0x30, End: 0x30, Kind: asm.WatchChange, Halt: true})
+	if err := m.WaitState(ctx, asm.StatePaused); err != nil {
+		t.Fatal(err)
+	}
+	if h := m.LastHalt(); h.Reason != asm.HaltWatchpoint || h.PC != 0x02 {
+		t.Errorf("watchpoint %s", h)
+	}
+	m.Stop()
+	if err := m.WaitState(ctx, asm.StateStopped); err != nil {
+		t.Fatal(err)
+	}
+}
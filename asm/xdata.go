@@ -0,0 +1,62 @@
+package asm
+
+// xdataDevice device handlers mapped in XDATA address range
+type xdataDevice struct {
+	start, end uint16 // address range, end included
+	read       func(m *Machine, addr uint16) uint8
+	write      func(m *Machine, addr uint16, val uint8)
+}
+
+// MapXDATA map device handlers to XDATA address range start~end, end included,
+// handlers get absolute address, later mapped range take precedence on overlap,
+// nil read read as 0xFF, nil write ignore the write, unmapped address is RAM
+func (m *Machine) MapXDATA(start, end uint16, read func(m *Machine, addr uint16) uint8, write func(m *Machine, addr uint16, val uint8)) {
+	m.xdataDevices = append(m.xdataDevices, xdataDevice{start: start, end: end, read: read, write: write})
+}
+
+// xdataDevice mapped device at address, nil if RAM
+func (m *Machine) xdataDevice(addr uint16) *xdataDevice {
+	for k := len(m.xdataDevices) - 1; k >= 0; k-- {
+		d := &m.xdataDevices[k]
+		if addr >= d.start && addr <= d.end {
+			return d
+		}
+	}
+	return nil
+}
+
+// ReadXDATA read mechine XDATA range, by mapped device or RAM
+func (m *Machine) ReadXDATA(addr uint16) uint8 {
+	m.xdataBus(addr)
+	val := uint8(0xFF)
+	if d := m.xdataDevice(addr); d == nil {
+		val = m.XDATA[addr]
+	} else if d.read != nil {
+		val = d.read(m, addr)
+	}
+	m.watch(SpaceXDATA, uint(addr), WatchRead, val, val)
+	return val
+}
+
+// WriteXDATA write mechine XDATA range, by mapped device or RAM
+func (m *Machine) WriteXDATA(addr uint16, val uint8) {
+	m.xdataBus(addr)
+	d := m.xdataDevice(addr)
+	if d == nil {
+		m.watch(SpaceXDATA, uint(addr), WatchWrite, m.XDATA[addr], val)
+		m.XDATA[addr] = val
+		return
+	}
+	// device old value unknown, not read to avoid side effects
+	m.watch(SpaceXDATA, uint(addr), WatchWrite, val, val)
+	if d.write != nil {
+		d.write(m, addr, val)
+	}
+}
+
+// xdataBus MOVX beyond on-chip XRAM access external bus
+func (m *Machine) xdataBus(addr uint16) {
+	if m.executing && int(addr) >= m.Variant.XRAMSize {
+		m.busAccess()
+	}
+}
@@ -0,0 +1,50 @@
+package asm_test
+
+import (
+	"testing"
+
+	"github.com/ma6254/go8051/asm"
+)
+
+func Test_XDATA_Device(t *testing.T) {
+	m := asm.NewMachine(asm.Frequency1MHz)
+	var writes []uint16
+	data := uint8(0)
+	// LCD controller, 0x8000: command/status, 0x8001: data
+	m.MapXDATA(0x8000, 0x8001, func(m *asm.Machine, addr uint16) uint8 {
+		if addr == 0x8000 {
+			return 0x00 // not busy
+		}
+		return data
+	}, func(m *asm.Machine, addr uint16, val uint8) {
+		writes = append(writes, addr)
+		if addr == 0x8001 {
+			data = val
+		}
+	})
+	m.ROM = []byte{
+		0x90, 0x80, 0x01, // 0000: MOV DPTR, #0x8001
+		0x74, 0x41, // 0003: MOV A, #0x41
+		0xF0,             // 0005: MOVX @DPTR, A
+		0x75, 0xA0, 0x80, // 0006: MOV P2, #0x80
+		0x78, 0x00, // 0009: MOV R0, #0x00
+		0xF2,       // 000B: MOVX @R0, A
+		0x08,       // 000C: INC R0
+		0xE2,       // 000D: MOVX A, @R0
+		0xF5, 0x40, // 000E: MOV 0x40, A
+		0x90, 0x12, 0x34, // 0010: MOV DPTR, #0x1234
+		0xF0, // 0013: MOVX @DPTR, A
+	}
+	for m.PC < uint(len(m.ROM)) {
+		m.Single()
+	}
+	if len(writes) != 2 || writes[0] != 0x8001 || writes[1] != 0x8000 {
+		t.Errorf("device writes %04X", writes)
+	}
+	if m.DATA[0x40] != 0x41 {
+		t.Errorf("device read %02X", m.DATA[0x40])
+	}
+	if m.XDATA[0x8001] != 0 || m.XDATA[0x1234] != 0x41 {
+		t.Errorf("RAM XDATA[8001] %02X XDATA[1234] %02X", m.XDATA[0x8001], m.XDATA[0x1234])
+	}
+}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"os"
 
 	"github.com/ma6254/go8051/asm"
 )
@@ -44,18 +46,14 @@ func main() {
 		0x75, 0x81, 0xf, //  C:0x002B MOV SP(0x81), #0x0F
 		0x2, 0x0, 0x17, // C:0x002E LJMP main(C:0017)
 	}
-	m.Trace(0x0028, func(m *asm.Machine) {
-		log.Printf("%04X R0:%02X\n", m.PC, m.DATA[asm.R0])
+	// trace main loop to stderr
+	rec := asm.NewTraceRecorder(0)
+	rec.AddRange(0x0017, 0x0024)
+	rec.Stream(os.Stderr, asm.TraceText)
+	m.Record(rec)
+	m.WatchPins(func(m *asm.Machine, port uint8, bit uint, level asm.PinLevel) {
+		log.Printf("%04X P%d.%d: %s\n", m.PC, (port-asm.P0)>>4, bit, level)
 	})
-	m.Trace(0x001C, func(m *asm.Machine) {
-		log.Printf("%04X P0: %02X\n", m.PC, m.DATA[asm.P0])
-	})
-	// m.Trace(0x04, func(m *asm.Machine) {
-	// 	log.Printf("%04X P0: %02X\n", m.PC, m.DATA[asm.P0])
-	// })
-	// m.Trace(0x07, func(m *asm.Machine) {
-	// 	log.Printf("%04X R0: %02X\n", m.PC, m.DATA[asm.R0])
-	// })
 
 	fakecodeString, err := m.DumpFakeCode()
 	if err != nil {
@@ -64,6 +62,8 @@ func main() {
 	}
 	fmt.Println(fakecodeString)
 	log.Print("8051 Machine Running")
-	m.Start()
+	ctx := context.Background()
+	m.Start(ctx)
+	m.WaitState(ctx, asm.StateStopped)
 	log.Printf("8051 Machine Stoped")
 }